@@ -91,3 +91,22 @@ func (c *Client) SendProcesses(processes []*gen.Process, auth *gen.Auth) error {
 
 	return err
 }
+
+// SendSystemMetrics sends a list of system metrics samples to the server
+func (c *Client) SendSystemMetrics(metrics []*gen.SystemMetrics, auth *gen.Auth) error {
+
+	req := &gen.SendSystemMetricsRequest{
+		Metrics: metrics,
+		Auth:    auth,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	_, err := c.client.SendSystemMetrics(ctx, connect.NewRequest(req))
+	if err != nil {
+		c.logger.Error().Err(err).Msg("Failed to send system metrics")
+	}
+
+	return err
+}
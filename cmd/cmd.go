@@ -67,6 +67,11 @@ func NewOdaCmd() *cobra.Command {
 		newServeCmd(),
 		newReloadCmd(),
 		newConfigCmd(),
+		newEmitCmd(),
+		newCommandsCmd(),
+		newPauseCmd(),
+		newResumeCmd(),
+		newIncognitoCmd(),
 	)
 
 	return odaCmd
@@ -203,19 +208,30 @@ func setupConfig() {
 
 	// Configure default user globals
 	user.Conf = &user.Config{
-		Os:      int64(osConf),
-		OsName:  osName,
-		HomeDir: homeDir,
-		OdaDir:  odaDir,
-		IsRoot:  isRoot,
-		ExePath: exePath,
-		User:    sudoExecUser,
+		Os:         int64(osConf),
+		OsName:     osName,
+		HomeDir:    homeDir,
+		OdaDir:     odaDir,
+		IsRoot:     isRoot,
+		ExePath:    exePath,
+		SocketPath: config.GetSocketPath(odaDir, sudoExecUser),
+		User:       sudoExecUser,
 	}
 
 	// run cleanup job
 	job.Cleanup(hours, days)
 }
 
+// getSocketPath returns the collector socket path stored at install, so the hooks, the daemon and the
+// command line agree on it. Installations without a stored path use the path of the current environment.
+func getSocketPath() string {
+	if conf, err := user.GetConfig(); err == nil && conf.SocketPath != "" {
+		return conf.SocketPath
+	}
+
+	return user.Conf.SocketPath
+}
+
 // Execute is the entry point for the command line
 func Execute() {
 	odaCmd := NewOdaCmd()
@@ -353,6 +369,7 @@ func install(cmd *cobra.Command, _ []string) error {
 		IsWorkspace:         isWorkspace,
 		ShellTypeToLocation: user.Conf.ShellTypeToLocation,
 		BaseCommandPath:     cmd.CommandPath(),
+		SocketPath:          user.Conf.SocketPath,
 	}
 	dmn := daemon.NewDaemon(daemonConf, logging.Log)
 
@@ -370,6 +387,8 @@ func install(cmd *cobra.Command, _ []string) error {
 			SudoExecUser:  user.Conf.User,
 			OdaDir:        user.Conf.OdaDir,
 			HomeDir:       user.Conf.HomeDir,
+			ExePath:       user.Conf.ExePath,
+			SocketPath:    user.Conf.SocketPath,
 		}
 
 		shl, err := shell.NewShell(shellConfig, logging.Log)
@@ -6,8 +6,11 @@ import (
 	"github.com/devzero-inc/oda/client"
 	"github.com/devzero-inc/oda/collector"
 	"github.com/devzero-inc/oda/config"
+	"github.com/devzero-inc/oda/filter"
+	"github.com/devzero-inc/oda/host"
 	"github.com/devzero-inc/oda/logging"
 	"github.com/devzero-inc/oda/process"
+	"github.com/devzero-inc/oda/redact"
 	"github.com/devzero-inc/oda/user"
 
 	"github.com/pkg/errors"
@@ -26,6 +29,7 @@ func newCollectCmd() *cobra.Command {
 
 	collectCmd.Flags().BoolP("auto-credentials", "a", false, "Try to automatically generate the credentails")
 	collectCmd.Flags().BoolP("workspace", "w", false, "Is collection executed in a DevZero workspace")
+	collectCmd.Flags().String("socket", "", "Path to the collector socket, defaults to the path configured at install")
 
 	return collectCmd
 }
@@ -45,6 +49,15 @@ func collect(cmd *cobra.Command, _ []string) error {
 		return errors.Wrap(err, "failed to get workspace flag")
 	}
 
+	socketPath, err := cmd.Flags().GetString("socket")
+	if err != nil {
+		logging.Log.Error().Err(err).Msg("Failed to get socket flag")
+		return errors.Wrap(err, "failed to get socket flag")
+	}
+	if socketPath == "" {
+		socketPath = getSocketPath()
+	}
+
 	user.Conf, err = user.GetConfig()
 	if err != nil {
 		logging.Log.Error().Err(err).Msg("Failed to get os config")
@@ -73,14 +86,46 @@ func collect(cmd *cobra.Command, _ []string) error {
 		CommandIntervalMultiplier: config.AppConfig.CommandIntervalMultiplier,
 		MaxConcurrentCommands:     config.AppConfig.MaxConcurrentCommands,
 		MaxDuration:               time.Duration(config.AppConfig.MaxDuration) * time.Second,
+		ReaperInterval:            time.Duration(config.AppConfig.ReaperInterval) * time.Second,
+		CommandTimeout:            time.Duration(config.AppConfig.CommandTimeout) * time.Second,
 	}
 
-	procCol, err := process.NewFactory(logging.Log).Create(config.AppConfig.ProcessCollectionType)
+	rules, err := collector.CompileRules(config.AppConfig.Rules)
+	if err != nil {
+		logging.Log.Error().Err(err).Msg("Failed to compile rules")
+		return errors.Wrap(err, "failed to compile rules")
+	}
+
+	redactor, err := redact.New(config.AppConfig.RedactPatterns)
+	if err != nil {
+		logging.Log.Error().Err(err).Msg("Failed to compile redact patterns")
+		return errors.Wrap(err, "failed to compile redact patterns")
+	}
+
+	filterEngine, err := filter.New(config.AppConfig.FilterConfig())
+	if err != nil {
+		logging.Log.Error().Err(err).Msg("Failed to compile filters")
+		return errors.Wrap(err, "failed to compile filters")
+	}
+
+	processingConfig := collector.ProcessingConfig{
+		Rules:    rules,
+		Redactor: redactor,
+		Filter:   filterEngine,
+	}
+
+	procCol, err := process.NewFactory(logging.Log, config.AppConfig.ProcRoot).Create(config.AppConfig.ProcessCollectionType)
 	if err != nil {
 		logging.Log.Error().Err(err).Msg("Failed to create process collector")
 		return errors.Wrap(err, "failed to create process collector")
 	}
 
+	// Host metrics are read from the proc filesystem, which is available on Linux only
+	var hostSampler host.Sampler
+	if config.OSType(user.Conf.Os) == config.Linux {
+		hostSampler = host.NewProcfs(logging.Log, config.AppConfig.ProcRoot, config.AppConfig.DiskPath)
+	}
+
 	auth := collector.AuthConfig{
 		UserID:      config.AppConfig.UserID,
 		TeamID:      config.AppConfig.TeamID,
@@ -113,14 +158,14 @@ func collect(cmd *cobra.Command, _ []string) error {
 	}
 
 	collectorInstance := collector.NewCollector(
-		collector.SocketPath,
+		socketPath,
 		grpcClient,
 		logging.Log,
 		intervalConfig,
+		processingConfig,
 		auth,
-		config.AppConfig.ExcludeRegex,
-		config.AppConfig.ExcludeCommands,
 		procCol,
+		hostSampler,
 	)
 
 	collectorInstance.Collect()
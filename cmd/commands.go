@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/devzero-inc/oda/collector"
+	"github.com/devzero-inc/oda/config"
+	"github.com/devzero-inc/oda/logging"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var commandsFlags struct {
+	tag      string
+	category string
+	since    time.Duration
+	limit    int
+}
+
+// newCommandsCmd creates a new commands command.
+func newCommandsCmd() *cobra.Command {
+	commandsCmd := &cobra.Command{
+		Use:   "commands",
+		Short: "List collected commands",
+		Long:  `List the latest commands collected by ODA, optionally filtered by tag or category.`,
+		Args:  cobra.NoArgs,
+		RunE:  listCommands,
+	}
+
+	commandsCmd.Flags().StringVarP(&commandsFlags.tag, "tag", "t", "", "Only list commands with the tag")
+	commandsCmd.Flags().StringVarP(&commandsFlags.category, "category", "c", "", "Only list commands in the category")
+	commandsCmd.Flags().DurationVarP(&commandsFlags.since, "since", "s", 24*time.Hour, "Only list commands started within the duration")
+	commandsCmd.Flags().IntVarP(&commandsFlags.limit, "limit", "l", 50, "Maximum number of commands to list, 0 for no limit")
+
+	return commandsCmd
+}
+
+func listCommands(_ *cobra.Command, _ []string) error {
+	setupConfig()
+
+	commands, err := collector.GetCommands(collector.CommandFilter{
+		Category: commandsFlags.category,
+		Tag:      commandsFlags.tag,
+		Since:    time.Now().Add(-commandsFlags.since).UnixMilli(),
+		Limit:    commandsFlags.limit,
+	})
+	if err != nil {
+		logging.Log.Error().Err(err).Msg("Failed to get commands")
+		return errors.Wrap(err, "failed to get commands")
+	}
+
+	w := tabwriter.NewWriter(config.SysConfig.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STARTED\tDURATION\tCATEGORY\tTAGS\tRESULT\tCOMMAND")
+	for _, command := range commands {
+		category := command.Category
+		if command.Subcategory != "" {
+			category += " " + command.Subcategory
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			time.UnixMilli(command.StartTime).Format(time.DateTime),
+			(time.Duration(command.ExecutionTime) * time.Millisecond).String(),
+			category,
+			strings.Join(command.Tags, ","),
+			command.Result,
+			command.Command,
+		)
+	}
+
+	return w.Flush()
+}
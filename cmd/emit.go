@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/devzero-inc/oda/collector"
+
+	"github.com/spf13/cobra"
+)
+
+// emitTimeout is the maximum time emit waits for the collector, it runs on every prompt so it has to stay short
+const emitTimeout = time.Second
+
+var emitFlags struct {
+	socket    string
+	command   string
+	directory string
+	user      string
+	uuid      string
+	pid       int64
+	result    string
+	status    string
+	timestamp string
+	duration  string
+	session   string
+	tty       string
+	shell     string
+	version   string
+	ssh       string
+	tmuxPane  string
+}
+
+// newEmitCmd creates a new emit command.
+func newEmitCmd() *cobra.Command {
+	emitCmd := &cobra.Command{
+		Use:       "emit start|end|session_start|session_end",
+		Short:     "Emit shell event to the collector",
+		Long:      `Emit command and session start and end events from the shell hooks to the ODA collector.`,
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{collector.PhaseStart, collector.PhaseEnd, collector.PhaseSessionStart, collector.PhaseSessionEnd},
+		Hidden:    true,
+		// Emit runs from the shell prompt, so it should never print usage or pollute the terminal
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          emit,
+	}
+
+	emitCmd.Flags().StringVar(&emitFlags.socket, "socket", "", "Path to the collector socket")
+	emitCmd.Flags().StringVar(&emitFlags.command, "command", "", "Command that is executed")
+	emitCmd.Flags().StringVar(&emitFlags.directory, "directory", "", "Directory where command is executed")
+	emitCmd.Flags().StringVar(&emitFlags.user, "user", "", "User that executed the command")
+	emitCmd.Flags().StringVar(&emitFlags.uuid, "uuid", "", "Unique identifier of the command")
+	emitCmd.Flags().Int64Var(&emitFlags.pid, "pid", 0, "PID of the shell executing the command")
+	emitCmd.Flags().StringVar(&emitFlags.result, "result", "", "Result of the command (success/failure)")
+	emitCmd.Flags().StringVar(&emitFlags.status, "status", "", "Exit status of the command")
+	emitCmd.Flags().StringVar(&emitFlags.timestamp, "timestamp", "", "Time of the event in seconds since epoch, as reported by the shell")
+	emitCmd.Flags().StringVar(&emitFlags.duration, "duration", "", "Duration of the command in milliseconds, as reported by the shell")
+	emitCmd.Flags().StringVar(&emitFlags.session, "session", "", "Unique identifier of the shell session")
+	emitCmd.Flags().StringVar(&emitFlags.tty, "tty", "", "Terminal of the shell session")
+	emitCmd.Flags().StringVar(&emitFlags.shell, "shell", "", "Name of the shell")
+	emitCmd.Flags().StringVar(&emitFlags.version, "shell-version", "", "Version of the shell")
+	emitCmd.Flags().StringVar(&emitFlags.ssh, "ssh-connection", "", "SSH connection of the shell session, empty for local sessions")
+	emitCmd.Flags().StringVar(&emitFlags.tmuxPane, "tmux-pane", "", "Tmux pane of the shell session")
+
+	// The path is templated into the hooks at install, emit does not read the configuration to find it
+	_ = emitCmd.MarkFlagRequired("socket")
+
+	return emitCmd
+}
+
+// emit intentionally skips setupConfig, it is executed for every command in the shell
+// and must not touch the database or the configuration files.
+func emit(_ *cobra.Command, args []string) error {
+	msg := &collector.Message{
+		Phase:        args[0],
+		Command:      emitFlags.command,
+		Directory:    emitFlags.directory,
+		User:         emitFlags.user,
+		UUID:         emitFlags.uuid,
+		PID:          emitFlags.pid,
+		Result:       emitFlags.result,
+		Status:       emitFlags.status,
+		Timestamp:    emitFlags.timestamp,
+		Duration:     emitFlags.duration,
+		Session:      emitFlags.session,
+		TTY:          emitFlags.tty,
+		Shell:        emitFlags.shell,
+		ShellVersion: emitFlags.version,
+		TmuxPane:     emitFlags.tmuxPane,
+		// Only the presence of the connection is stored, the addresses are not needed
+		SSH: emitFlags.ssh != "",
+	}
+
+	if err := collector.SendMessage(emitFlags.socket, msg, emitTimeout); err != nil {
+		return fmt.Errorf("failed to emit %s event: %w", msg.Phase, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/devzero-inc/oda/collector"
+	"github.com/devzero-inc/oda/config"
+	"github.com/devzero-inc/oda/logging"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// incognitoEnv is the environment variable honored by the shell hooks, commands are not emitted while it is set
+const incognitoEnv = "ODA_INCOGNITO"
+
+var pauseFlags struct {
+	duration time.Duration
+}
+
+// newPauseCmd creates a new pause command.
+func newPauseCmd() *cobra.Command {
+	pauseCmd := &cobra.Command{
+		Use:   "pause",
+		Short: "Pause recording of commands",
+		Long:  `Pause recording of commands until resumed or until the given duration passes.`,
+		Args:  cobra.NoArgs,
+		RunE:  pause,
+	}
+
+	pauseCmd.Flags().DurationVar(&pauseFlags.duration, "for", 0, "Resume recording automatically after the duration, e.g. 30m")
+
+	return pauseCmd
+}
+
+// newResumeCmd creates a new resume command.
+func newResumeCmd() *cobra.Command {
+	resumeCmd := &cobra.Command{
+		Use:   "resume",
+		Short: "Resume recording of commands",
+		Long:  `Resume recording of commands paused with oda pause.`,
+		Args:  cobra.NoArgs,
+		RunE:  resume,
+	}
+
+	return resumeCmd
+}
+
+// newIncognitoCmd creates a new incognito command.
+func newIncognitoCmd() *cobra.Command {
+	incognitoCmd := &cobra.Command{
+		Use:   "incognito",
+		Short: "Start a shell that is not recorded",
+		Long: `Start a new shell in which commands are not recorded by ODA.
+Other shells are still recorded, exit the shell to leave incognito mode.`,
+		Args: cobra.NoArgs,
+		RunE: incognito,
+	}
+
+	return incognitoCmd
+}
+
+func pause(_ *cobra.Command, _ []string) error {
+	if pauseFlags.duration < 0 {
+		return fmt.Errorf("pause duration can not be negative")
+	}
+
+	setupConfig()
+
+	msg := &collector.Message{
+		Phase: collector.PhasePause,
+		Kind:  collector.PauseKindPause,
+	}
+	if pauseFlags.duration > 0 {
+		msg.Duration = strconv.FormatInt(pauseFlags.duration.Milliseconds(), 10)
+	}
+
+	if err := collector.SendMessage(getSocketPath(), msg, emitTimeout); err != nil {
+		logging.Log.Error().Err(err).Msg("Failed to pause recording")
+		return pkgerrors.Wrap(err, "failed to pause recording, is the collector running")
+	}
+
+	if pauseFlags.duration > 0 {
+		fmt.Fprintf(config.SysConfig.Out, "Recording of commands paused for %s\n", pauseFlags.duration)
+	} else {
+		fmt.Fprintln(config.SysConfig.Out, "Recording of commands paused, run oda resume to continue")
+	}
+
+	return nil
+}
+
+func resume(_ *cobra.Command, _ []string) error {
+	setupConfig()
+
+	msg := &collector.Message{Phase: collector.PhaseResume}
+
+	if err := collector.SendMessage(getSocketPath(), msg, emitTimeout); err != nil {
+		logging.Log.Error().Err(err).Msg("Failed to resume recording")
+		return pkgerrors.Wrap(err, "failed to resume recording, is the collector running")
+	}
+
+	fmt.Fprintln(config.SysConfig.Out, "Recording of commands resumed")
+
+	return nil
+}
+
+// incognito starts the shell of the user with the incognito environment variable set, the window
+// is recorded by the collector so the gap in the recorded commands is shown as intentional
+func incognito(_ *cobra.Command, _ []string) error {
+	if os.Getenv(incognitoEnv) != "" {
+		return fmt.Errorf("already in incognito mode")
+	}
+
+	setupConfig()
+	socketPath := getSocketPath()
+
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+
+	id := fmt.Sprintf("incognito-%d-%d", time.Now().UnixNano(), os.Getpid())
+
+	start := &collector.Message{
+		Phase: collector.PhasePause,
+		Kind:  collector.PauseKindIncognito,
+		UUID:  id,
+	}
+	if err := collector.SendMessage(socketPath, start, emitTimeout); err != nil {
+		// Commands are still not recorded, only the window is missing on the dashboard
+		logging.Log.Warn().Err(err).Msg("Failed to record incognito window")
+		fmt.Fprintf(config.SysConfig.ErrOut, "Collector is not reachable, the incognito window is not shown on the dashboard: %s\n", err)
+	}
+
+	fmt.Fprintln(config.SysConfig.Out, "Incognito mode, commands in this shell are not recorded. Exit the shell to leave.")
+
+	shellCmd := exec.Command(shell)
+	shellCmd.Stdin = os.Stdin
+	shellCmd.Stdout = os.Stdout
+	shellCmd.Stderr = os.Stderr
+	shellCmd.Env = append(os.Environ(), incognitoEnv+"=1")
+
+	err := shellCmd.Run()
+
+	end := &collector.Message{
+		Phase: collector.PhaseResume,
+		Kind:  collector.PauseKindIncognito,
+		UUID:  id,
+	}
+	if sendErr := collector.SendMessage(socketPath, end, emitTimeout); sendErr != nil {
+		logging.Log.Warn().Err(sendErr).Msg("Failed to end incognito window")
+	}
+
+	// The exit status of the last command in the shell is not an error of incognito
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		logging.Log.Error().Err(err).Msg("Failed to run incognito shell")
+		return pkgerrors.Wrap(err, "failed to run incognito shell")
+	}
+
+	fmt.Fprintln(config.SysConfig.Out, "Left incognito mode")
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package collector
+
+import "github.com/devzero-inc/oda/process"
+
+// maxProcessTreeDepth bounds the walk up the process tree, protecting against cycles caused by PID reuse
+const maxProcessTreeDepth = 64
+
+// commandsByShellPID maps the PID of each shell with a running command to the UUID of that command.
+// When a shell has multiple running commands, like background jobs, the most recently started one wins.
+func (c *Collector) commandsByShellPID() map[int64]string {
+	c.collectionConfig.commandsMutex.Lock()
+	defer c.collectionConfig.commandsMutex.Unlock()
+
+	shells := make(map[int64]string, len(c.collectionConfig.ongoingCommands))
+	started := make(map[int64]int64, len(c.collectionConfig.ongoingCommands))
+	for uuid, command := range c.collectionConfig.ongoingCommands {
+		if command.PID <= 0 {
+			continue
+		}
+		if startTime, ok := started[command.PID]; ok && startTime > command.StartTime {
+			continue
+		}
+		shells[command.PID] = uuid
+		started[command.PID] = command.StartTime
+	}
+
+	return shells
+}
+
+// attributeProcesses sets the command ID of every process that descends from a shell with a running command.
+// The shell process itself is not attributed, as it outlives the command.
+func attributeProcesses(processes []process.Process, shells map[int64]string) {
+	if len(shells) == 0 {
+		return
+	}
+
+	parents := make(map[int64]int64, len(processes))
+	for _, p := range processes {
+		parents[p.PID] = p.PPID
+	}
+
+	for i := range processes {
+		ppid := processes[i].PPID
+		for depth := 0; depth < maxProcessTreeDepth && ppid > 1; depth++ {
+			if uuid, ok := shells[ppid]; ok {
+				processes[i].CommandID = uuid
+				break
+			}
+
+			next, ok := parents[ppid]
+			if !ok {
+				break
+			}
+			ppid = next
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/devzero-inc/oda/process"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributeProcesses(t *testing.T) {
+	processes := []process.Process{
+		{PID: 1, PPID: 0, Name: "init"},
+		{PID: 100, PPID: 1, Name: "bash"},
+		{PID: 101, PPID: 100, Name: "make"},
+		{PID: 102, PPID: 101, Name: "go"},
+		{PID: 103, PPID: 102, Name: "compile"},
+		{PID: 200, PPID: 1, Name: "zsh"},
+		{PID: 201, PPID: 200, Name: "vim"},
+		{PID: 300, PPID: 301, Name: "loop-a"},
+		{PID: 301, PPID: 300, Name: "loop-b"},
+	}
+
+	attributeProcesses(processes, map[int64]string{100: "cmd-1"})
+
+	commandIDs := make(map[string]string)
+	for _, p := range processes {
+		commandIDs[p.Name] = p.CommandID
+	}
+
+	assert.Equal(t, map[string]string{
+		"init":    "",
+		"bash":    "",
+		"make":    "cmd-1",
+		"go":      "cmd-1",
+		"compile": "cmd-1",
+		"zsh":     "",
+		"vim":     "",
+		"loop-a":  "",
+		"loop-b":  "",
+	}, commandIDs)
+}
+
+func TestCommandsByShellPID(t *testing.T) {
+	c := &Collector{
+		collectionConfig: collectionConfig{
+			ongoingCommands: map[string]Command{
+				"old":    {PID: 100, StartTime: 1},
+				"new":    {PID: 100, StartTime: 2},
+				"other":  {PID: 200, StartTime: 1},
+				"no-pid": {StartTime: 1},
+			},
+		},
+	}
+
+	assert.Equal(t, map[int64]string{100: "new", 200: "other"}, c.commandsByShellPID())
+}
@@ -1,16 +1,19 @@
 package collector
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"net"
-	"strconv"
-	"strings"
 	"sync"
 
 	"github.com/devzero-inc/oda/client"
+	"github.com/devzero-inc/oda/filter"
 	gen "github.com/devzero-inc/oda/gen/api/v1"
+	"github.com/devzero-inc/oda/host"
 	"github.com/devzero-inc/oda/process"
+	"github.com/devzero-inc/oda/redact"
 	"github.com/devzero-inc/oda/util"
 
 	"github.com/rs/zerolog"
@@ -18,21 +21,18 @@ import (
 	"time"
 )
 
-// TODO move this to /var/run or other appropriate location based on OS,
-// TODO /var/run has issues with permisisons os have to explore a bit more.
-const SocketPath = "/tmp/oda.socket"
-
 // Collector collects command and system information
 type Collector struct {
 	socketPath       string
 	client           *client.Client
 	logger           zerolog.Logger
-	excludeRegex     string
-	excludeCommands  []string
 	collectionConfig collectionConfig
 	authConfig       AuthConfig
 	protoAuthConfig  *gen.Auth
 	intervalConfig   IntervalConfig
+	processingConfig ProcessingConfig
+	// resolveGit resolves the Git context of the directory of a start message, replaced with a fake in tests
+	resolveGit func(path string) (*util.GitContext, error)
 }
 
 // IntervalConfig contains the configuration for the collection intervals
@@ -42,6 +42,20 @@ type IntervalConfig struct {
 	CommandIntervalMultiplier float64
 	MaxConcurrentCommands     int
 	MaxDuration               time.Duration
+	// ReaperInterval is the interval in which abandoned commands are looked for
+	ReaperInterval time.Duration
+	// CommandTimeout is the duration after which a command is considered abandoned even if its shell is still alive
+	CommandTimeout time.Duration
+}
+
+// ProcessingConfig contains the configuration for processing the commands before they are stored
+type ProcessingConfig struct {
+	// Rules are the user defined rules to categorize and tag commands
+	Rules []Rule
+	// Redactor removes secrets from commands before they are stored or sent
+	Redactor *redact.Redactor
+	// Filter decides which commands and processes are collected
+	Filter *filter.Engine
 }
 
 // AuthConfig contains the configuration for the command processing and authentication
@@ -56,8 +70,14 @@ type AuthConfig struct {
 type collectionConfig struct {
 	// ongoingCommands is a map of currently running commands
 	ongoingCommands map[string]Command
-	// collectionMutex is a mutex to protect the ongoingCommands map
-	collectionMutex sync.Mutex
+	// commandsMutex is a mutex to protect the ongoingCommands map, which is changed by the event loop and read by the sampler
+	commandsMutex sync.Mutex
+	// events are the received messages waiting for the event loop
+	events chan event
+	// writes are the database writes of the event loop waiting for the writer
+	writes chan write
+	// sampleRequests is the pending process snapshot, buffered by one so requests are coalesced
+	sampleRequests chan struct{}
 	// activeCommandsCounter is a counter for the number of active commands
 	activeCommandsCounter int
 	// collectionContext is the context for the collection process
@@ -68,10 +88,18 @@ type collectionConfig struct {
 	isCollectionRunning bool
 	// process is the system process collector
 	process process.SystemProcess
+	// runs diffs consecutive process snapshots to detect started and exited processes
+	runs *process.RunTracker
+	// host is the host metrics sampler, nil if host metrics are not collected on the platform
+	host host.Sampler
+	// pause is the state of the global pause, guarded by commandsMutex
+	pause pauseState
+	// ordering releases the received events in the order their connections were accepted
+	ordering ordering
 }
 
 // NewCollector creates a new collector instance
-func NewCollector(socketPath string, client *client.Client, logger zerolog.Logger, config IntervalConfig, auth AuthConfig, excludeRegex string, excludeCommands []string, process process.SystemProcess) *Collector {
+func NewCollector(socketPath string, client *client.Client, logger zerolog.Logger, config IntervalConfig, processing ProcessingConfig, auth AuthConfig, systemProcess process.SystemProcess, sampler host.Sampler) *Collector {
 
 	collector := &Collector{
 		socketPath: socketPath,
@@ -79,12 +107,17 @@ func NewCollector(socketPath string, client *client.Client, logger zerolog.Logge
 		logger:     logger,
 		collectionConfig: collectionConfig{
 			ongoingCommands: make(map[string]Command),
-			process:         process,
+			events:          make(chan event, eventQueueSize),
+			writes:          make(chan write, writeQueueSize),
+			sampleRequests:  make(chan struct{}, 1),
+			process:         systemProcess,
+			runs:            process.NewRunTracker(),
+			host:            sampler,
 		},
-		intervalConfig:  config,
-		authConfig:      auth,
-		excludeRegex:    excludeRegex,
-		excludeCommands: excludeCommands,
+		intervalConfig:   config,
+		processingConfig: processing,
+		authConfig:       auth,
+		resolveGit:       util.GetGitContext,
 	}
 
 	if auth.TeamID != "" && auth.UserEmail != "" {
@@ -106,6 +139,9 @@ func (c *Collector) Collect() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	c.restoreOngoingCommands()
+	c.restorePause()
+
 	var wg sync.WaitGroup
 
 	wg.Add(1)
@@ -114,6 +150,24 @@ func (c *Collector) Collect() {
 		c.collectSystemInformation(ctx, c.intervalConfig.ProcessInterval, 3, c.intervalConfig.MaxDuration)
 	}()
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.runSampler(ctx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.processEvents(ctx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.runWriter()
+	}()
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -138,10 +192,8 @@ func (c *Collector) collectSystemInformation(ctx context.Context, initialDuratio
 			c.logger.Debug().Msg("Shutting down collection of system information")
 			return
 		case <-time.After(currentDuration):
-			// Perform the collection on each tick
-			if err := c.collectOnce(); err != nil {
-				c.logger.Error().Err(err).Msg("Failed to collect system information")
-			}
+			// Request the collection on each tick, the sampler skips it if a snapshot is already pending
+			c.requestSample()
 
 			// Calculate the next interval with exponential backoff
 			currentDuration = time.Duration(float64(currentDuration) * increaseFactor)
@@ -164,6 +216,18 @@ func (c *Collector) collectOnce() error {
 		return err
 	}
 
+	// All processes of a single sample share the stored time, so samples can be aggregated per command
+	sampledAt := time.Now().UnixMilli()
+	for i := range processes {
+		processes[i].StoredTime = sampledAt
+	}
+
+	// Processes are filtered only after attribution, so excluded processes do not break the process tree
+	attributeProcesses(processes, c.commandsByShellPID())
+	processes = c.filterProcesses(processes)
+
+	// The runs are tracked first, so the snapshot keeps the created times of the runs the processes belong to
+	c.trackProcessRuns(processes, sampledAt)
 	if err := process.InsertProcesses(processes); err != nil {
 		c.logger.Error().Err(err).Msg("Failed to insert processes")
 	}
@@ -185,19 +249,99 @@ func (c *Collector) collectOnce() error {
 		}()
 	}
 
+	c.collectHostMetrics(sampledAt)
+
 	return nil
 }
 
-func (c *Collector) onStartCommand() {
-	c.collectionConfig.collectionMutex.Lock()
-	defer c.collectionConfig.collectionMutex.Unlock()
+// trackProcessRuns diffs the snapshot against the previous one and records the lifetimes of the processes,
+// after a restart the runs left open are used as the previous snapshot so processes that exited meanwhile are closed
+func (c *Collector) trackProcessRuns(processes []process.Process, sampledAt int64) {
+	runs := c.collectionConfig.runs
 
-	// Perform initial collection for every command
-	if err := c.collectOnce(); err != nil {
-		c.logger.Error().Err(err).Msg("Failed to collect system information")
+	if !runs.Seeded() {
+		open, err := process.GetOpenRuns()
+		if err != nil {
+			c.logger.Error().Err(err).Msg("Failed to fetch open process runs")
+		}
+		runs.Seed(open)
 	}
 
+	events := runs.Track(processes, sampledAt)
+	for _, event := range events {
+		c.logger.Debug().Str("event", event.Type).Int64("pid", event.PID).Str("name", event.Name).Msg("Process lifecycle event")
+	}
+
+	if err := process.RecordRuns(processes, events); err != nil {
+		c.logger.Error().Err(err).Msg("Failed to record process runs")
+	}
+}
+
+// collectHostMetrics samples the host metrics at the same time as the processes,
+// a failed sample is logged and skipped so it does not affect the process collection
+func (c *Collector) collectHostMetrics(sampledAt int64) {
+	if c.collectionConfig.host == nil {
+		return
+	}
+
+	metrics, err := c.collectionConfig.host.Collect()
+	if err != nil {
+		c.logger.Error().Err(err).Msg("Failed to collect host metrics")
+		return
+	}
+	metrics.StoredTime = sampledAt
+
+	if err := host.InsertMetrics(metrics); err != nil {
+		c.logger.Error().Err(err).Msg("Failed to insert host metrics")
+	}
+
+	if c.client != nil {
+		systemMetrics := []*gen.SystemMetrics{host.MapMetricsToProto(metrics)}
+
+		go func() {
+			if err := c.client.SendSystemMetrics(systemMetrics, c.protoAuthConfig); err != nil {
+				c.logger.Error().Err(err).Msg("Failed to send host metrics")
+			}
+		}()
+	}
+}
+
+// filterProcesses removes the processes excluded by the filters, the command lines and working directories
+// of the remaining ones are dropped when excluded and the command lines are redacted like commands
+func (c *Collector) filterProcesses(processes []process.Process) []process.Process {
+	filtered := processes[:0]
+	for _, p := range processes {
+		if !c.processingConfig.Filter.AllowProcess(p.Name) {
+			continue
+		}
+
+		allowCmdline, allowCwd := c.processingConfig.Filter.AllowProcessDetails(p.Cmdline, p.Cwd)
+		if allowCmdline {
+			p.Cmdline = process.TruncateCmdline(c.processingConfig.Redactor.Redact(p.Cmdline))
+		} else {
+			p.Cmdline = ""
+		}
+		if !allowCwd {
+			p.Cwd = ""
+		}
+
+		filtered = append(filtered, p)
+	}
+	return filtered
+}
+
+// onStartCommand requests an initial snapshot for the command and starts the high-frequency collection,
+// it is called from the event loop only.
+func (c *Collector) onStartCommand() {
+	c.requestSample()
+
 	c.collectionConfig.activeCommandsCounter++
+	c.startCommandCollection()
+}
+
+// startCommandCollection starts the high-frequency collection if it is not running already,
+// it is called from the event loop only.
+func (c *Collector) startCommandCollection() {
 	// If the collection is not running, start it with a timeout
 	if !c.collectionConfig.isCollectionRunning {
 		c.logger.Debug().Msg("Starting collection")
@@ -213,10 +357,33 @@ func (c *Collector) onStartCommand() {
 	}
 }
 
-func (c *Collector) onEndCommand() {
-	c.collectionConfig.collectionMutex.Lock()
-	defer c.collectionConfig.collectionMutex.Unlock()
+// restoreOngoingCommands loads commands that were running when the collector was stopped,
+// so their end messages are still matched and their duration includes the downtime.
+func (c *Collector) restoreOngoingCommands() {
+	commands, err := GetOngoingCommands()
+	if err != nil {
+		c.logger.Error().Err(err).Msg("Failed to restore ongoing commands")
+		return
+	}
+
+	if len(commands) == 0 {
+		return
+	}
+
+	c.logger.Info().Msgf("Restored %d ongoing commands", len(commands))
+
+	c.collectionConfig.commandsMutex.Lock()
+	for uuid, command := range commands {
+		c.collectionConfig.ongoingCommands[uuid] = command
+	}
+	c.collectionConfig.commandsMutex.Unlock()
+
+	c.collectionConfig.activeCommandsCounter += len(commands)
+	c.startCommandCollection()
+}
 
+// onEndCommand stops the high-frequency collection after the last active command, it is called from the event loop only.
+func (c *Collector) onEndCommand() {
 	c.collectionConfig.activeCommandsCounter--
 	// If there are no more active commands, stop the collection
 	if c.collectionConfig.activeCommandsCounter == 0 && c.collectionConfig.isCollectionRunning {
@@ -227,12 +394,7 @@ func (c *Collector) onEndCommand() {
 }
 
 func (c *Collector) collectCommandInformation() error {
-	if err := util.Fs.RemoveAll(SocketPath); err != nil {
-		c.logger.Error().Err(err).Msg("Failed to clean up existing socket")
-		return err
-	}
-
-	listener, err := net.Listen("unix", SocketPath)
+	listener, err := listen(c.socketPath)
 	if err != nil {
 		c.logger.Error().Err(err).Msg("Failed to listen on UNIX socket")
 		return err
@@ -264,109 +426,198 @@ func (c *Collector) collectCommandInformation() error {
 			}
 		}
 
+		// The sequence number is taken in the accept loop, so it follows the order the shell sent the messages in
+		seq := c.acceptSequence()
+
 		semaphore <- struct{}{} // Acquire
 		go func(conn net.Conn) {
 			defer func() {
 				<-semaphore // Release
 			}()
-			if err := c.handleSocketCollection(conn); err != nil {
+			if err := verifyPeer(conn); err != nil {
+				c.logger.Warn().Err(err).Msg("Rejected connection to the collector socket")
+				conn.Close()
+				c.release(seq, nil)
+				return
+			}
+			if err := c.handleSocketCollection(conn, seq); err != nil {
 				c.logger.Error().Err(err).Msg("Error handling socket collection")
 			}
 		}(conn)
 	}
 }
 
-func (c *Collector) handleSocketCollection(con net.Conn) error {
+// handleSocketCollection reads a single message from the connection and queues it for the event loop
+// once the messages of all connections accepted before it were queued
+func (c *Collector) handleSocketCollection(con net.Conn, seq uint64) error {
 	defer con.Close()
-	var buf [1024]byte
-	n, err := con.Read(buf[:])
-	if err != nil {
-		c.logger.Error().Err(err).Msg("Error reading from socket")
+
+	// The sequence number is released on every path, otherwise the events of later connections would wait forever
+	var ev *event
+	defer func() {
+		c.release(seq, ev)
+	}()
+
+	// A client that does not send its message holds back the later ones, so it is given only a short time
+	if err := con.SetReadDeadline(time.Now().Add(messageReadTimeout)); err != nil {
 		return err
 	}
 
-	data := string(buf[:n])
-	parts := strings.Split(data, "|")
+	// Limit the amount of data a single connection can send, the frame header is small
+	// so a bit of additional space on top of the payload is more than enough
+	reader := bufio.NewReader(io.LimitReader(con, MaxMessageSize+64))
+
+	msg, err := DecodeMessage(reader)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("Invalid command format")
+		return err
+	}
+	receivedAt := time.Now()
 
-	c.logger.Debug().Msgf("Received: %s", string(buf[:n]))
+	// The raw command is not logged, it is redacted only once the start message is handled
+	c.logger.Debug().Msgf("Received %s message for command %s", msg.Phase, msg.UUID)
 
-	if len(parts) != 8 {
-		c.logger.Error().Msg("Invalid command format")
-		return fmt.Errorf("invalid command format")
+	if !isKnownPhase(msg.Phase) {
+		c.logger.Error().Msgf("Invalid command phase: %s", msg.Phase)
+		return fmt.Errorf("invalid command phase: %s", msg.Phase)
 	}
 
-	if parts[0] == "start" {
-		if err := c.handleStartCommand(parts); err != nil {
-			c.logger.Error().Err(err).Msg("Error handling start command")
-		}
-	} else if parts[0] == "end" {
-		if err := c.handleEndCommand(parts); err != nil {
-			c.logger.Error().Err(err).Msg("Error handling end command")
+	received := event{msg: msg, receivedAt: receivedAt}
+
+	// Git is run here rather than in the event loop, so a slow repository does not hold up the sampling
+	if msg.Phase == PhaseStart && c.processingConfig.Filter.AllowCommand(msg.Command, msg.Directory) {
+		git, err := c.resolveGit(msg.Directory)
+		if err != nil {
+			c.logger.Debug().Err(err).Msg("Command is not executed in a Git repository")
 		}
-	} else {
-		c.logger.Error().Msg("Invalid command format")
-		return err
+		received.git = git
 	}
+	ev = &received
 
 	return nil
 }
 
-func (c *Collector) handleStartCommand(parts []string) error {
-	if !IsCommandAcceptable(parts[1], c.excludeRegex, c.excludeCommands) {
+func (c *Collector) handleStartCommand(msg *Message, git *util.GitContext, receivedAt time.Time) error {
+	c.collectionConfig.commandsMutex.Lock()
+	paused := c.isPaused(receivedAt)
+	c.collectionConfig.commandsMutex.Unlock()
+
+	if paused {
+		c.logger.Debug().Msg("Recording of commands is paused")
+		return nil
+	}
+
+	if !c.processingConfig.Filter.AllowCommand(msg.Command, msg.Directory) {
 		c.logger.Debug().Msg("Command is not acceptable")
 		return fmt.Errorf("command is not acceptable")
 	}
 
-	c.logger.Debug().Msgf("Parsing command: %s", parts[0])
+	// Secrets are redacted before the command is parsed, persisted or sent anywhere
+	msg.Command = c.processingConfig.Redactor.Redact(msg.Command)
 
-	repo, err := util.GetRepoNameFromConfig(parts[2])
-	if err != nil {
-		c.logger.Error().Err(err).Msg("Failed to get repository name")
-	}
+	c.logger.Debug().Msgf("Parsing command: %s", msg.Command)
 
-	pid, _ := strconv.ParseInt(parts[5], 10, 64)
+	category, subcategory := CategorizeCommand(msg.Command)
 
 	command := Command{
-		Category:   ParseCommand(parts[1]),
-		Command:    parts[1],
-		Directory:  parts[2],
-		User:       parts[3],
-		StartTime:  time.Now().UnixMilli(), // TODO: there are some issues with sending time through shell because of ms support on MAC, explore more
-		Repository: repo,
-		PID:        pid,
+		UUID:        msg.UUID,
+		Category:    category,
+		Subcategory: subcategory,
+		Command:     msg.Command,
+		Directory:   msg.Directory,
+		User:        msg.User,
+		PID:         msg.PID,
+		SessionID:   msg.Session,
 	}
 
-	c.collectionConfig.ongoingCommands[parts[4]] = command
+	if git != nil {
+		command.Repository = git.Name()
+		command.RepositoryID = git.RepositoryID
+		command.RepositoryPath = git.Path
+		command.GitBranch = git.Branch
+		command.GitCommit = git.Commit
+		command.GitDirty = git.Dirty
+	}
+
+	if !c.processingConfig.Filter.AllowRepository(command.RepositoryID) {
+		c.logger.Debug().Msg("Command repository is not acceptable")
+		return fmt.Errorf("command repository is not acceptable")
+	}
+
+	applyStartTime(&command, msg, receivedAt)
+	applyRules(&command, c.processingConfig.Rules)
 
-	c.onStartCommand()
+	c.collectionConfig.commandsMutex.Lock()
+	_, exists := c.collectionConfig.ongoingCommands[msg.UUID]
+	c.collectionConfig.ongoingCommands[msg.UUID] = command
+	c.collectionConfig.commandsMutex.Unlock()
+
+	c.persist("persist ongoing command", func() error {
+		return InsertOngoingCommand(msg.UUID, command)
+	})
+
+	// Repeated start for the same command must not be counted twice, the end message will come only once
+	if !exists {
+		c.onStartCommand()
+	}
 
 	return nil
 }
 
-func (c *Collector) handleEndCommand(parts []string) error {
-
-	if !IsCommandAcceptable(parts[1], c.excludeRegex, c.excludeCommands) {
+func (c *Collector) handleEndCommand(msg *Message, receivedAt time.Time) error {
+	if !c.processingConfig.Filter.AllowCommand(msg.Command, msg.Directory) {
 		c.logger.Debug().Msg("Command is not acceptable")
 		return fmt.Errorf("command is not acceptable")
 	}
 
-	c.logger.Debug().Msgf("Parsing command: %s", parts[0])
+	c.logger.Debug().Msgf("Ending command: %s", msg.UUID)
+
+	c.collectionConfig.commandsMutex.Lock()
+	command, exists := c.collectionConfig.ongoingCommands[msg.UUID]
+	delete(c.collectionConfig.ongoingCommands, msg.UUID)
+	paused := c.isPaused(receivedAt)
+	c.collectionConfig.commandsMutex.Unlock()
+
+	// Commands started while paused were never recorded, so their end is expected to be unmatched
+	if !exists && paused {
+		return nil
+	}
+
+	if !exists {
+		c.logger.Error().Msg("Matching start command not found")
+		return fmt.Errorf("matching start command not found")
+	}
+
+	applyEndTime(&command, msg, receivedAt)
+	command.Result = msg.Result
+	command.Status = msg.Status
+
+	c.finishCommand(msg.UUID, command)
+
+	return nil
+}
+
+// finishCommand stops the collection if it was the last active command and queues
+// storing the finished command for the writer, which then sends it to the remote server.
+func (c *Collector) finishCommand(uuid string, command Command) {
+	c.onEndCommand()
 
-	if command, exists := c.collectionConfig.ongoingCommands[parts[4]]; exists {
-		command.EndTime = time.Now().UnixMilli()
-		command.ExecutionTime = command.EndTime - command.StartTime
-		command.Result = parts[6]
-		command.Status = parts[7]
+	c.persist("store command", func() error {
+		if err := DeleteOngoingCommand(uuid); err != nil {
+			c.logger.Error().Err(err).Msg("Failed to delete ongoing command")
+		}
+
+		samples, err := process.GetCommandSamples(uuid)
+		if err != nil {
+			c.logger.Error().Err(err).Msg("Failed to get command resource samples")
+		}
+		summarizeResources(&command, samples)
 
 		c.logger.Debug().Msgf("Command: %+v", command)
 		if err := InsertCommand(command); err != nil {
-			c.logger.Error().Err(err).Msg("Failed to insert command")
 			return err
 		}
 
-		delete(c.collectionConfig.ongoingCommands, parts[4])
-		c.onEndCommand()
-
 		if c.client != nil {
 			go func() {
 				if err := c.client.SendCommands([]*gen.Command{MapCommandToProto(command)}, c.protoAuthConfig); err != nil {
@@ -374,10 +625,7 @@ func (c *Collector) handleEndCommand(parts []string) error {
 				}
 			}()
 		}
-	} else {
-		c.logger.Error().Msg("Matching start command not found")
-		return fmt.Errorf("matching start command not found")
-	}
 
-	return nil
+		return nil
+	})
 }
@@ -0,0 +1,215 @@
+package collector
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devzero-inc/oda/config"
+	"github.com/devzero-inc/oda/database"
+	"github.com/devzero-inc/oda/filter"
+	"github.com/devzero-inc/oda/host"
+	"github.com/devzero-inc/oda/process"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeProcess is a process collector returning a fixed list of processes
+type fakeProcess struct {
+	processes []process.Process
+}
+
+func (f *fakeProcess) Collect() ([]process.Process, error) {
+	return f.processes, nil
+}
+
+// fakeHost is a host metrics sampler returning fixed metrics or an error
+type fakeHost struct {
+	metrics host.Metrics
+	err     error
+}
+
+func (f *fakeHost) Collect() (host.Metrics, error) {
+	return f.metrics, f.err
+}
+
+// setupTestDatabase sets up an in-memory database with all migrations applied
+func setupTestDatabase(t *testing.T) {
+	t.Helper()
+
+	config.SetupSysConfig()
+
+	db, err := sqlx.Connect("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to connect to the database: %s", err)
+	}
+	// Every connection to an in-memory database gets its own database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	database.DB = db
+	database.RunMigrations()
+}
+
+func TestHandleStartCommandRedactsSecrets(t *testing.T) {
+	setupTestDatabase(t)
+
+	c := NewCollector("", nil, zerolog.Nop(), IntervalConfig{}, ProcessingConfig{}, AuthConfig{}, &fakeProcess{}, nil)
+	c.collectionConfig.isCollectionRunning = true
+
+	assert.NoError(t, c.handleStartCommand(&Message{Phase: PhaseStart, Command: "export API_TOKEN=abc123", UUID: "1", Directory: t.TempDir()}, nil, time.Now()))
+
+	assert.Equal(t, "export API_TOKEN=[REDACTED]", c.collectionConfig.ongoingCommands["1"].Command)
+
+	runWrites(t, c)
+	persisted, err := GetOngoingCommands()
+	assert.NoError(t, err)
+	assert.Equal(t, "export API_TOKEN=[REDACTED]", persisted["1"].Command)
+}
+
+func TestCollectOnceFiltersProcesses(t *testing.T) {
+	setupTestDatabase(t)
+
+	engine, err := filter.New(filter.Config{Processes: filter.List{Exclude: []string{"^sh$"}}})
+	assert.NoError(t, err)
+
+	procs := &fakeProcess{processes: []process.Process{
+		{PID: 100, PPID: 1, Name: "bash"},
+		{PID: 101, PPID: 100, Name: "sh"},
+		{PID: 102, PPID: 101, Name: "make"},
+	}}
+
+	c := NewCollector("", nil, zerolog.Nop(), IntervalConfig{}, ProcessingConfig{Filter: engine}, AuthConfig{}, procs, nil)
+	c.collectionConfig.ongoingCommands["cmd"] = Command{PID: 100}
+
+	assert.NoError(t, c.collectOnce())
+
+	processes, err := process.GetProcessesForCommand("cmd")
+	assert.NoError(t, err)
+	assert.Len(t, processes, 1)
+	assert.Equal(t, "make", processes[0].Name)
+}
+
+func TestCollectOnceStoresHostMetrics(t *testing.T) {
+	setupTestDatabase(t)
+
+	procs := &fakeProcess{processes: []process.Process{{PID: 100, PPID: 1, Name: "make"}}}
+	sampler := &fakeHost{metrics: host.Metrics{CPUUsage: 80, Load1: 3.5, MemoryTotal: 4096, MemoryUsed: 3072}}
+
+	c := NewCollector("", nil, zerolog.Nop(), IntervalConfig{}, ProcessingConfig{}, AuthConfig{}, procs, sampler)
+
+	assert.NoError(t, c.collectOnce())
+
+	sampler.err = errors.New("proc filesystem is not mounted")
+	assert.NoError(t, c.collectOnce(), "Failed host metrics should not fail the process collection")
+
+	metrics, err := host.GetMetricsForPeriod(0, time.Now().UnixMilli())
+	assert.NoError(t, err)
+	if !assert.Len(t, metrics, 1) {
+		return
+	}
+
+	assert.Equal(t, 80.0, metrics[0].CPUUsage)
+	assert.Equal(t, int64(3072), metrics[0].MemoryUsed)
+
+	var storedTime int64
+	assert.NoError(t, database.DB.Get(&storedTime, "SELECT MIN(stored_time) FROM process_samples"))
+	assert.Equal(t, storedTime, metrics[0].StoredTime, "Host metrics should share the stored time of the process sample")
+}
+
+func TestCollectOnceTracksProcessRuns(t *testing.T) {
+	setupTestDatabase(t)
+
+	procs := &fakeProcess{processes: []process.Process{
+		{PID: 100, PPID: 1, Name: "bash", CreatedTime: 1000, IntervalCPUUsage: 5, RSSBytes: 2048},
+		{PID: 101, PPID: 100, Name: "make", CreatedTime: 2000, IntervalCPUUsage: 50},
+	}}
+
+	c := NewCollector("", nil, zerolog.Nop(), IntervalConfig{}, ProcessingConfig{}, AuthConfig{}, procs, nil)
+	c.collectionConfig.ongoingCommands["cmd"] = Command{PID: 100}
+
+	assert.NoError(t, c.collectOnce())
+
+	procs.processes = []process.Process{
+		{PID: 100, PPID: 1, Name: "bash", CreatedTime: 1000, IntervalCPUUsage: 20, RSSBytes: 1024},
+		{PID: 102, PPID: 100, Name: "go", CreatedTime: 3000},
+	}
+	assert.NoError(t, c.collectOnce())
+
+	runs, err := process.GetRunsForPeriod(0, time.Now().UnixMilli())
+	assert.NoError(t, err)
+	if !assert.Len(t, runs, 3) {
+		return
+	}
+	byPID := make(map[int64]*process.Run)
+	for _, run := range runs {
+		byPID[run.PID] = run
+	}
+
+	assert.Equal(t, int64(2), byPID[100].Samples)
+	assert.Zero(t, byPID[100].ExitedTime)
+	assert.Equal(t, 20.0, byPID[100].PeakCPUUsage)
+	assert.Equal(t, int64(2048), byPID[100].PeakRSSBytes)
+	assert.NotZero(t, byPID[101].ExitedTime, "Process missing from the snapshot should be marked as exited")
+	assert.Equal(t, "cmd", byPID[101].CommandID)
+	assert.Zero(t, byPID[102].ExitedTime)
+
+	// A restarted collector closes the runs of the processes that exited while it was stopped
+	procs.processes = []process.Process{{PID: 100, PPID: 1, Name: "bash", CreatedTime: 1000}}
+	restarted := NewCollector("", nil, zerolog.Nop(), IntervalConfig{}, ProcessingConfig{}, AuthConfig{}, procs, nil)
+	assert.NoError(t, restarted.collectOnce())
+
+	open, err := process.GetOpenRuns()
+	assert.NoError(t, err)
+	if assert.Len(t, open, 1) {
+		assert.Equal(t, int64(100), open[0].PID)
+		assert.Equal(t, int64(3), open[0].Samples)
+	}
+}
+
+func TestCollectOnceRedactsProcessDetails(t *testing.T) {
+	setupTestDatabase(t)
+
+	engine, err := filter.New(filter.Config{
+		Commands:    filter.List{Exclude: []string{"^pass "}},
+		Directories: filter.List{Exclude: []string{"^/home/dev/private"}},
+	})
+	assert.NoError(t, err)
+
+	procs := &fakeProcess{processes: []process.Process{
+		{PID: 100, PPID: 1, Name: "bash", Cmdline: "-bash", Cwd: "/home/dev"},
+		{PID: 101, PPID: 100, Name: "curl", Cmdline: "curl -H 'Authorization: Bearer abc123' https://example.com", Cwd: "/home/dev/private/notes"},
+		{PID: 102, PPID: 100, Name: "pass", Cmdline: "pass show github", Cwd: "/home/dev"},
+		// The token crosses the length limit, cut off first it would be too short to be detected
+		{PID: 103, PPID: 100, Name: "java", Cmdline: "java -cp " + strings.Repeat("a", 4070) + " ghp_" + strings.Repeat("aBcDeFgHiJ", 4), Cwd: "/home/dev"},
+	}}
+
+	c := NewCollector("", nil, zerolog.Nop(), IntervalConfig{}, ProcessingConfig{Filter: engine}, AuthConfig{}, procs, nil)
+	c.collectionConfig.ongoingCommands["cmd"] = Command{PID: 100}
+
+	assert.NoError(t, c.collectOnce())
+
+	processes, err := process.GetProcessesForCommand("cmd")
+	assert.NoError(t, err)
+	details := make(map[string]*process.Process)
+	for _, p := range processes {
+		details[p.Name] = p
+	}
+
+	if assert.Contains(t, details, "curl") {
+		assert.NotContains(t, details["curl"].Cmdline, "abc123")
+		assert.Contains(t, details["curl"].Cmdline, "https://example.com")
+		assert.Empty(t, details["curl"].Cwd)
+	}
+	if assert.Contains(t, details, "pass") {
+		assert.Empty(t, details["pass"].Cmdline)
+		assert.Equal(t, "/home/dev", details["pass"].Cwd)
+	}
+	if assert.Contains(t, details, "java") {
+		assert.NotContains(t, details["java"].Cmdline, "aBcDeFgHiJ")
+		assert.True(t, strings.HasSuffix(details["java"].Cmdline, "[REDACTED]"))
+	}
+}
@@ -1,10 +1,9 @@
 package collector
 
 import (
-	"regexp"
+	"encoding/json"
 	"time"
 
-	"github.com/devzero-inc/oda/config"
 	"github.com/devzero-inc/oda/database"
 	gen "github.com/devzero-inc/oda/gen/api/v1"
 	"github.com/devzero-inc/oda/logging"
@@ -13,7 +12,9 @@ import (
 // Command is the model for command
 type Command struct {
 	Id            int64  `json:"id" db:"id"`
+	UUID          string `json:"uuid" db:"uuid"`
 	Category      string `json:"category" db:"category"`
+	Subcategory   string `json:"subcategory" db:"subcategory"`
 	Command       string `json:"command" db:"command"`
 	User          string `json:"user" db:"user"`
 	Directory     string `json:"directory" db:"directory"`
@@ -24,6 +25,40 @@ type Command struct {
 	Result        string `json:"result" db:"result"`
 	Repository    string `json:"repository" db:"repository"`
 	PID           int64  `json:"pid" db:"pid"`
+	// StartSkew is the difference in milliseconds between receiving the start message and the shell timestamp
+	StartSkew int64 `json:"start_skew" db:"start_skew"`
+	// EndSkew is the difference in milliseconds between receiving the end message and the shell timestamp
+	EndSkew int64 `json:"end_skew" db:"end_skew"`
+	// GitBranch is the branch checked out when the command started
+	GitBranch string `json:"git_branch" db:"git_branch"`
+	// GitCommit is the SHA of HEAD when the command started
+	GitCommit string `json:"git_commit" db:"git_commit"`
+	// GitDirty reports whether the working tree had uncommitted changes when the command started, nil if unknown
+	GitDirty *bool `json:"git_dirty" db:"git_dirty"`
+	// RepositoryID is the normalized origin remote in the form of host/org/repo
+	RepositoryID string `json:"repository_id" db:"repository_id"`
+	// RepositoryPath is the directory relative to the repository root
+	RepositoryPath string `json:"repository_path" db:"repository_path"`
+	// PeakCPUUsage is the highest CPU usage of the command's process tree in a single sample
+	PeakCPUUsage float64 `json:"peak_cpu_usage" db:"peak_cpu_usage"`
+	// AvgCPUUsage is the average CPU usage of the command's process tree over all samples
+	AvgCPUUsage float64 `json:"avg_cpu_usage" db:"avg_cpu_usage"`
+	// CPUSeconds is the CPU time consumed by the command's process tree, estimated from the samples
+	CPUSeconds float64 `json:"cpu_seconds" db:"cpu_seconds"`
+	// PeakMemoryUsage is the highest memory usage of the command's process tree in a single sample
+	PeakMemoryUsage float64 `json:"peak_memory_usage" db:"peak_memory_usage"`
+	// SessionID is the identifier of the shell session in which the command was executed
+	SessionID string `json:"session_id" db:"session_id"`
+	// Tags are the tags added by the user defined rules, stored in the command_tags table
+	Tags []string `json:"tags" db:"-"`
+}
+
+// CommandFilter contains the criteria for listing commands, empty fields are ignored
+type CommandFilter struct {
+	Category string
+	Tag      string
+	Since    int64
+	Limit    int
 }
 
 // GetCommandById fetches a command by its ID
@@ -36,20 +71,81 @@ func GetCommandById(id int64) (*Command, error) {
 		return nil, err
 	}
 
+	tags, err := GetCommandTags(id)
+	if err != nil {
+		return nil, err
+	}
+	command.Tags = tags
+
 	return &command, nil
 }
 
-// GetAllCommandsForPeriod fetches all commands for a given period
-func GetAllCommandsForPeriod(start int64, end int64) ([]*Command, error) {
+// GetCommandTags fetches the tags of a command
+func GetCommandTags(id int64) ([]string, error) {
+	var tags []string
+
+	if err := database.DB.Select(&tags, `SELECT tag FROM command_tags WHERE command_id = ? ORDER BY tag ASC`, id); err != nil {
+		logging.Log.Err(err).Msg("Failed to get command tags")
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// GetCommands fetches the latest commands matching the filter together with their tags
+func GetCommands(filter CommandFilter) ([]Command, error) {
+	var rows []struct {
+		Command
+		Tag *string `db:"tag"`
+	}
+
+	// The tags are joined to the page of commands, so they are loaded with the same query instead of one per command
+	query := `SELECT c.id, c.category, c.subcategory, c.command, c.directory, c.repository_id, c.git_branch, c.execution_time, c.start_time, c.result, t.tag
+              FROM (SELECT * FROM commands
+                    WHERE start_time >= ?
+                    AND (? = '' OR category = ?)
+                    AND (? = '' OR id IN (SELECT command_id FROM command_tags WHERE tag = ?))
+                    ORDER BY start_time DESC
+                    LIMIT ?) AS c
+              LEFT JOIN command_tags t ON t.command_id = c.id
+              ORDER BY c.start_time DESC, c.id, t.tag ASC;`
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+
+	if err := database.DB.Select(&rows, query, filter.Since, filter.Category, filter.Category, filter.Tag, filter.Tag, limit); err != nil {
+		logging.Log.Err(err).Msg("Failed to get commands")
+		return nil, err
+	}
+
+	var commands []Command
+	for _, row := range rows {
+		if len(commands) == 0 || commands[len(commands)-1].Id != row.Id {
+			commands = append(commands, row.Command)
+		}
+		if row.Tag != nil {
+			last := &commands[len(commands)-1]
+			last.Tags = append(last.Tags, *row.Tag)
+		}
+	}
+
+	return commands, nil
+}
+
+// GetAllCommandsForPeriod fetches all commands for a given period, optionally only the ones with the given tag
+func GetAllCommandsForPeriod(start int64, end int64, tag string) ([]*Command, error) {
 	var commands []*Command
 
 	query := `SELECT id, category, SUM(execution_time) AS execution_time 
               FROM commands 
               WHERE start_time BETWEEN ? AND ? 
+              AND (? = '' OR id IN (SELECT command_id FROM command_tags WHERE tag = ?))
               GROUP BY category 
               ORDER BY category ASC, SUM(execution_time) DESC;`
 
-	if err := database.DB.Select(&commands, query, start, end); err != nil {
+	if err := database.DB.Select(&commands, query, start, end, tag, tag); err != nil {
 		logging.Log.Err(err).Msg("Failed to get aggregated commands with start and end times")
 		return nil, err
 	}
@@ -57,17 +153,20 @@ func GetAllCommandsForPeriod(start int64, end int64) ([]*Command, error) {
 	return commands, nil
 }
 
-// GetAllCommandsForCategoryForPeriod fetches all commands for a given category and period
-func GetAllCommandsForCategoryForPeriod(category string, start int64, end int64) ([]Command, error) {
+// GetAllCommandsForCategoryForPeriod fetches all commands for a given category and period, optionally only the ones with the given tag
+func GetAllCommandsForCategoryForPeriod(category string, start int64, end int64, tag string) ([]Command, error) {
 	var commands []Command
 
-	query := `SELECT id, category, command, SUM(execution_time) AS execution_time 
+	query := `SELECT id, category, subcategory, command, SUM(execution_time) AS execution_time,
+              MAX(peak_cpu_usage) AS peak_cpu_usage, AVG(avg_cpu_usage) AS avg_cpu_usage,
+              SUM(cpu_seconds) AS cpu_seconds, MAX(peak_memory_usage) AS peak_memory_usage
               FROM commands 
               WHERE category = ? AND start_time BETWEEN ? AND ? 
+              AND (? = '' OR id IN (SELECT command_id FROM command_tags WHERE tag = ?))
               GROUP BY command 
               ORDER BY command ASC, SUM(execution_time) DESC;`
 
-	if err := database.DB.Select(&commands, query, category, start, end); err != nil {
+	if err := database.DB.Select(&commands, query, category, start, end, tag, tag); err != nil {
 		logging.Log.Err(err).Msg("Failed to get aggregated commands with start and end times")
 		return nil, err
 	}
@@ -75,85 +174,132 @@ func GetAllCommandsForCategoryForPeriod(category string, start int64, end int64)
 	return commands, nil
 }
 
-// DeleteCommandsByDays deletes records older than n days
+// DeleteCommandsByDays deletes commands started more than n days ago together with their tags
 func DeleteCommandsByDays(days int) error {
 	// Calculate the time when old records will be deleted
-	timeToDelete := time.Now().AddDate(0, 0, -days).Unix()
+	timeToDelete := time.Now().AddDate(0, 0, -days).UnixMilli()
 
-	result, err := database.DB.Exec("DELETE FROM commands WHERE stored_time < ?", timeToDelete)
+	result, err := database.DB.Exec("DELETE FROM commands WHERE start_time < ?", timeToDelete)
 	if err != nil {
 		return err
 	}
 
-	_, err = result.RowsAffected()
+	if _, err := result.RowsAffected(); err != nil {
+		return err
+	}
+
+	_, err = database.DB.Exec("DELETE FROM command_tags WHERE command_id NOT IN (SELECT id FROM commands)")
 
 	return err
 }
 
-// InsertCommand inserts a command into the database
+// InsertCommand inserts a command together with its tags into the database
 func InsertCommand(command Command) error {
-	query := `INSERT INTO commands (category, command, user, directory, execution_time, start_time, end_time, status, result, repository, pid)
-	VALUES (:category, :command, :user, :directory, :execution_time, :start_time, :end_time, :status, :result, :repository, :pid)`
+	query := `INSERT INTO commands (uuid, category, subcategory, command, user, directory, execution_time, start_time, end_time, status, result, repository, pid, start_skew, end_skew, git_branch, git_commit, git_dirty, repository_id, repository_path, peak_cpu_usage, avg_cpu_usage, cpu_seconds, peak_memory_usage, session_id)
+	VALUES (:uuid, :category, :subcategory, :command, :user, :directory, :execution_time, :start_time, :end_time, :status, :result, :repository, :pid, :start_skew, :end_skew, :git_branch, :git_commit, :git_dirty, :repository_id, :repository_path, :peak_cpu_usage, :avg_cpu_usage, :cpu_seconds, :peak_memory_usage, :session_id)`
 
-	_, err := database.DB.NamedExec(query, command)
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		return err
+	}
 
-	return err
-}
+	result, err := tx.NamedExec(query, command)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, tag := range command.Tags {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO command_tags (command_id, tag) VALUES (?, ?)`, id, tag); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
 
-// ParseCommand extracts the command name from a command string.
-func ParseCommand(command string) string {
+	return tx.Commit()
+}
 
-	// TODO: there might be some other cases as well like: watch, time etc
-	var pattern = regexp.MustCompile(`^(?:sudo|nohup)?\s*(?:\./|/usr/bin/|/bin/|/usr/local/bin/)?([^/ ]+?)(?:\s|$)`)
+// ongoingCommand is the model for a command that has started but not yet ended
+type ongoingCommand struct {
+	UUID       string `db:"uuid"`
+	Command    string `db:"command"`
+	StoredTime int64  `db:"stored_time"`
+}
 
-	matches := pattern.FindStringSubmatch(command)
-	if len(matches) > 1 {
-		return matches[1]
+// InsertOngoingCommand persists a started command, so it survives collector restarts
+func InsertOngoingCommand(uuid string, command Command) error {
+	data, err := json.Marshal(command)
+	if err != nil {
+		return err
 	}
 
-	return command
+	query := `INSERT OR REPLACE INTO ongoing_commands (uuid, command, stored_time) VALUES (?, ?, ?)`
+
+	_, err = database.DB.Exec(query, uuid, string(data), time.Now().UnixMilli())
+
+	return err
 }
 
-// IsCommandAcceptable checks if a command string matches a configured regex pattern.
-// Commands that match the regex are considered unacceptable, and it returns false.
-// If the regex is empty or the command does not match, it returns true.
-func IsCommandAcceptable(command string, excludeRegex string, excludeCommands []string) bool {
-	if excludeRegex != "" {
-		logging.Log.Debug().Msgf("Checking if command %s is acceptable for regex: %s", command, config.AppConfig.ExcludeRegex)
-		var pattern = regexp.MustCompile(excludeRegex)
-		return !pattern.MatchString(command)
-	}
-
-	if len(excludeCommands) > 0 {
-		logging.Log.Debug().Msgf("Checking if command %s is acceptable for commands: %v", command, excludeCommands)
-		acceptable := true
-		for _, excludeCommand := range excludeCommands {
-			var pattern = regexp.MustCompile(excludeCommand)
-			acceptable = !pattern.MatchString(command)
-			if !acceptable {
-				break
-			}
-		}
+// DeleteOngoingCommand removes a persisted started command
+func DeleteOngoingCommand(uuid string) error {
+	_, err := database.DB.Exec("DELETE FROM ongoing_commands WHERE uuid = ?", uuid)
 
-		return acceptable
+	return err
+}
+
+// GetOngoingCommands fetches all persisted started commands mapped by their UUID
+func GetOngoingCommands() (map[string]Command, error) {
+	var rows []ongoingCommand
+
+	if err := database.DB.Select(&rows, `SELECT uuid, command, stored_time FROM ongoing_commands`); err != nil {
+		logging.Log.Err(err).Msg("Failed to get ongoing commands")
+		return nil, err
 	}
 
-	return true
+	commands := make(map[string]Command, len(rows))
+	for _, row := range rows {
+		var command Command
+		if err := json.Unmarshal([]byte(row.Command), &command); err != nil {
+			logging.Log.Err(err).Msgf("Failed to decode ongoing command %s", row.UUID)
+			continue
+		}
+		commands[row.UUID] = command
+	}
+
+	return commands, nil
 }
 
 func MapCommandToProto(command Command) *gen.Command {
 	return &gen.Command{
-		Id:            command.Id,
-		Category:      command.Category,
-		Command:       command.Command,
-		User:          command.User,
-		Directory:     command.Directory,
-		ExecutionTime: command.ExecutionTime,
-		StartTime:     command.StartTime,
-		EndTime:       command.EndTime,
-		Status:        command.Status,
-		Result:        command.Result,
-		Repository:    command.Repository,
-		Pid:           command.PID,
+		Id:              command.Id,
+		Category:        command.Category,
+		Subcategory:     command.Subcategory,
+		Command:         command.Command,
+		User:            command.User,
+		Directory:       command.Directory,
+		ExecutionTime:   command.ExecutionTime,
+		StartTime:       command.StartTime,
+		EndTime:         command.EndTime,
+		Status:          command.Status,
+		Result:          command.Result,
+		Repository:      command.Repository,
+		Pid:             command.PID,
+		GitBranch:       command.GitBranch,
+		GitCommit:       command.GitCommit,
+		GitDirty:        command.GitDirty,
+		RepositoryId:    command.RepositoryID,
+		RepositoryPath:  command.RepositoryPath,
+		PeakCpuUsage:    command.PeakCPUUsage,
+		AvgCpuUsage:     command.AvgCPUUsage,
+		CpuSeconds:      command.CPUSeconds,
+		PeakMemoryUsage: command.PeakMemoryUsage,
+		SessionId:       command.SessionID,
+		Tags:            command.Tags,
 	}
 }
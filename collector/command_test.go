@@ -1,6 +1,14 @@
 package collector
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/devzero-inc/oda/database"
+	"github.com/devzero-inc/oda/process"
+
+	"github.com/stretchr/testify/assert"
+)
 
 // TestParseCommand tests the ParseCommand function with various command inputs.
 func TestParseCommand(t *testing.T) {
@@ -28,3 +36,119 @@ func TestParseCommand(t *testing.T) {
 		}
 	}
 }
+
+func TestOngoingCommandsPersistence(t *testing.T) {
+	setupTestDatabase(t)
+
+	command := Command{Category: "make", Command: "make build", Directory: "/src", StartTime: 1000, PID: 42}
+
+	assert.NoError(t, InsertOngoingCommand("first", command))
+	assert.NoError(t, InsertOngoingCommand("second", command))
+	assert.NoError(t, DeleteOngoingCommand("second"))
+
+	commands, err := GetOngoingCommands()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]Command{"first": command}, commands)
+}
+
+func TestCommandResourceSummary(t *testing.T) {
+	setupTestDatabase(t)
+
+	assert.NoError(t, process.InsertProcesses([]process.Process{
+		{PID: 10, Name: "make", StoredTime: 1_000, CPUUsage: 1, IntervalCPUUsage: 10, MemoryUsage: 1, CommandID: "cmd"},
+		{PID: 11, Name: "cc", StoredTime: 1_000, CPUUsage: 5, IntervalCPUUsage: 90, MemoryUsage: 3, CommandID: "cmd"},
+		{PID: 11, Name: "cc", StoredTime: 2_000, CPUUsage: 5, IntervalCPUUsage: 50, MemoryUsage: 2, CommandID: "cmd"},
+		{PID: 12, Name: "vim", StoredTime: 1_000, CPUUsage: 1, IntervalCPUUsage: 100, MemoryUsage: 9},
+	}))
+
+	samples, err := process.GetCommandSamples("cmd")
+	assert.NoError(t, err)
+
+	command := Command{UUID: "cmd", Command: "make build", StartTime: 0, EndTime: 3_000}
+	summarizeResources(&command, samples)
+	assert.NoError(t, InsertCommand(command))
+
+	stored, err := GetCommandById(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, stored.PeakCPUUsage)
+	assert.Equal(t, 75.0, stored.AvgCPUUsage)
+	assert.Equal(t, 1.5, stored.CPUSeconds)
+	assert.Equal(t, 4.0, stored.PeakMemoryUsage)
+
+	aggregated, err := GetAllCommandsForCategoryForPeriod("", 0, 1, "")
+	assert.NoError(t, err)
+	assert.Len(t, aggregated, 1)
+	assert.Equal(t, 1.5, aggregated[0].CPUSeconds)
+}
+
+func TestCommandTags(t *testing.T) {
+	setupTestDatabase(t)
+
+	assert.NoError(t, InsertCommand(Command{Category: "make", Command: "make build", StartTime: 1_000, ExecutionTime: 10, Tags: []string{"build", "ci"}}))
+	assert.NoError(t, InsertCommand(Command{Category: "make", Command: "make test", StartTime: 2_000, ExecutionTime: 20, Tags: []string{"tests"}}))
+	assert.NoError(t, InsertCommand(Command{Category: "git", Command: "git status", StartTime: 3_000, ExecutionTime: 30}))
+
+	command, err := GetCommandById(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"build", "ci"}, command.Tags)
+
+	aggregated, err := GetAllCommandsForPeriod(0, 5_000, "build")
+	assert.NoError(t, err)
+	assert.Len(t, aggregated, 1)
+	assert.Equal(t, int64(10), aggregated[0].ExecutionTime)
+
+	aggregated, err = GetAllCommandsForPeriod(0, 5_000, "")
+	assert.NoError(t, err)
+	assert.Len(t, aggregated, 2)
+
+	commands, err := GetCommands(CommandFilter{Tag: "tests"})
+	assert.NoError(t, err)
+	assert.Len(t, commands, 1)
+	assert.Equal(t, "make test", commands[0].Command)
+	assert.Equal(t, []string{"tests"}, commands[0].Tags)
+
+	commands, err = GetCommands(CommandFilter{Category: "make", Since: 1_500})
+	assert.NoError(t, err)
+	assert.Len(t, commands, 1)
+
+	commands, err = GetCommands(CommandFilter{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "git status", commands[0].Command)
+	assert.Len(t, commands, 2)
+
+	// Commands with several tags are returned once, the limit applies to the commands and not to their tags
+	commands, err = GetCommands(CommandFilter{})
+	assert.NoError(t, err)
+	if assert.Len(t, commands, 3) {
+		assert.Empty(t, commands[0].Tags)
+		assert.Equal(t, []string{"tests"}, commands[1].Tags)
+		assert.Equal(t, []string{"build", "ci"}, commands[2].Tags)
+	}
+
+	commands, err = GetCommands(CommandFilter{Category: "make", Limit: 1, Since: 500})
+	assert.NoError(t, err)
+	if assert.Len(t, commands, 1) {
+		assert.Equal(t, "make test", commands[0].Command)
+	}
+}
+
+func TestDeleteCommandsByDays(t *testing.T) {
+	setupTestDatabase(t)
+
+	old := time.Now().AddDate(0, 0, -10).UnixMilli()
+	recent := time.Now().UnixMilli()
+	assert.NoError(t, InsertCommand(Command{Category: "make", Command: "make build", StartTime: old, Tags: []string{"build"}}))
+	assert.NoError(t, InsertCommand(Command{Category: "make", Command: "make test", StartTime: recent, Tags: []string{"tests"}}))
+
+	assert.NoError(t, DeleteCommandsByDays(7))
+
+	commands, err := GetCommands(CommandFilter{})
+	assert.NoError(t, err)
+	if assert.Len(t, commands, 1) {
+		assert.Equal(t, "make test", commands[0].Command)
+	}
+
+	var tags []string
+	assert.NoError(t, database.DB.Select(&tags, "SELECT tag FROM command_tags"))
+	assert.Equal(t, []string{"tests"}, tags, "Tags of deleted commands should be deleted")
+}
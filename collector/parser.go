@@ -0,0 +1,311 @@
+package collector
+
+import (
+	"strings"
+)
+
+// wrapper describes a command that executes another command, like sudo or time
+type wrapper struct {
+	// argFlags are the short and long flags that consume the following word as their value
+	argFlags map[string]bool
+	// positional is the number of positional arguments before the wrapped command, like the duration of timeout
+	positional int
+	// assignments reports whether NAME=value words are accepted before the wrapped command, like in env
+	assignments bool
+}
+
+// flags builds a set of flags
+func flags(names ...string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// wrappers are commands that are unwrapped to find the command that is actually executed
+var wrappers = map[string]wrapper{
+	"sudo":    {argFlags: flags("-u", "-g", "-h", "-p", "-C", "-D", "-r", "-t", "-U", "-T", "--user", "--group", "--host", "--prompt", "--chdir", "--role", "--type", "--other-user", "--command-timeout", "--close-from")},
+	"doas":    {argFlags: flags("-u", "-C")},
+	"env":     {argFlags: flags("-u", "-C", "-S", "--unset", "--chdir", "--split-string"), assignments: true},
+	"time":    {argFlags: flags("-f", "-o", "--format", "--output")},
+	"watch":   {argFlags: flags("-n", "-d", "-q", "--interval", "--equexit")},
+	"xargs":   {argFlags: flags("-I", "-L", "-n", "-P", "-s", "-d", "-E", "-a", "--max-lines", "--max-args", "--max-procs", "--max-chars", "--delimiter", "--arg-file", "--process-slot-var")},
+	"nice":    {argFlags: flags("-n", "--adjustment")},
+	"ionice":  {argFlags: flags("-c", "-n", "-p", "--class", "--classdata")},
+	"timeout": {argFlags: flags("-s", "-k", "--signal", "--kill-after"), positional: 1},
+	"stdbuf":  {argFlags: flags("-i", "-o", "-e", "--input", "--output", "--error")},
+	"nohup":   {},
+	"exec":    {argFlags: flags("-a")},
+	"command": {},
+	"builtin": {},
+	"noglob":  {},
+}
+
+// bookkeeping are commands that only change the shell state, they are skipped when a chain
+// contains a more meaningful command, e.g. `cd api && make build` is categorized as make.
+var bookkeeping = flags("cd", "pushd", "popd", "export", "unset", "set", "source", ".", "alias", "unalias", "true", "false", ":", "clear", "echo")
+
+// subcommandTools are tools whose first argument is a subcommand worth recording, mapped to their
+// global flags that consume a value, e.g. `git -C dir commit` or `kubectl -n ns get`.
+var subcommandTools = map[string]map[string]bool{
+	"git":       flags("-C", "-c", "--git-dir", "--work-tree", "--namespace"),
+	"kubectl":   flags("-n", "--namespace", "--context", "--kubeconfig", "--cluster", "--user", "-s", "--server"),
+	"helm":      flags("-n", "--namespace", "--kube-context", "--kubeconfig"),
+	"docker":    flags("-H", "--host", "-c", "--context", "--config", "-l", "--log-level"),
+	"podman":    flags("--connection", "--url", "--log-level"),
+	"go":        flags(),
+	"cargo":     flags("--color", "-Z", "--config"),
+	"npm":       flags("--prefix", "-w", "--workspace"),
+	"pnpm":      flags("-C", "--dir", "--filter", "-F"),
+	"yarn":      flags("--cwd"),
+	"bun":       flags("--cwd"),
+	"deno":      flags(),
+	"pip":       flags(),
+	"pip3":      flags(),
+	"poetry":    flags(),
+	"uv":        flags("--directory", "--project"),
+	"terraform": flags("-chdir"),
+	"gh":        flags("-R", "--repo"),
+	"aws":       flags("--profile", "--region", "--output"),
+	"gcloud":    flags("--project", "--account", "--configuration"),
+	"az":        flags("--subscription"),
+	"brew":      flags(),
+	"apt":       flags("-o"),
+	"apt-get":   flags("-o"),
+	"dnf":       flags(),
+	"systemctl": flags("-H", "--host", "-M", "--machine"),
+	"dotnet":    flags(),
+	"mix":       flags(),
+	"oda":       flags(),
+}
+
+// shell operators separating commands in pipelines and chains
+const (
+	tokenWord = iota
+	tokenOperator
+)
+
+type token struct {
+	kind  int
+	value string
+}
+
+// tokenize splits the command line into words and operators following the shell quoting rules.
+// It is lenient with malformed input, an unterminated quote simply extends to the end of the line.
+func tokenize(command string) []token {
+	var tokens []token
+	var word strings.Builder
+	inWord := false
+
+	flush := func() {
+		if inWord {
+			tokens = append(tokens, token{kind: tokenWord, value: word.String()})
+			word.Reset()
+			inWord = false
+		}
+	}
+
+	for i := 0; i < len(command); i++ {
+		ch := command[i]
+		switch {
+		case ch == '\\':
+			inWord = true
+			if i+1 < len(command) {
+				i++
+				// Escaped newline is a line continuation
+				if command[i] != '\n' {
+					word.WriteByte(command[i])
+				}
+			}
+		case ch == '\'':
+			inWord = true
+			end := strings.IndexByte(command[i+1:], '\'')
+			if end < 0 {
+				word.WriteString(command[i+1:])
+				i = len(command)
+			} else {
+				word.WriteString(command[i+1 : i+1+end])
+				i += end + 1
+			}
+		case ch == '"':
+			inWord = true
+			for i++; i < len(command) && command[i] != '"'; i++ {
+				if command[i] == '\\' && i+1 < len(command) && strings.IndexByte("\"\\$`", command[i+1]) >= 0 {
+					i++
+				}
+				word.WriteByte(command[i])
+			}
+		case ch == '#' && !inWord:
+			// Comment until the end of the line
+			for i < len(command) && command[i] != '\n' {
+				i++
+			}
+			i--
+		case ch == ' ' || ch == '\t':
+			flush()
+		case ch == '\n' || ch == ';' || ch == '(' || ch == ')':
+			flush()
+			tokens = append(tokens, token{kind: tokenOperator, value: string(ch)})
+		case ch == '&' && inWord && (strings.HasSuffix(word.String(), ">") || strings.HasSuffix(word.String(), "<")):
+			// Duplication of a file descriptor like 2>&1 or <&3, the target is read as the rest of the word
+			word.WriteByte(ch)
+		case ch == '&' && i+1 < len(command) && command[i+1] == '>':
+			// Redirection of both outputs like &>log or &>>log is a word of its own
+			flush()
+			inWord = true
+			word.WriteByte(ch)
+		case ch == '|' || ch == '&':
+			flush()
+			op := string(ch)
+			if i+1 < len(command) && (command[i+1] == ch || (ch == '|' && command[i+1] == '&')) {
+				op += string(command[i+1])
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenOperator, value: op})
+		default:
+			inWord = true
+			word.WriteByte(ch)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// splitCommands splits the tokens on operators into the words of the individual simple commands
+func splitCommands(tokens []token) [][]string {
+	var commands [][]string
+	var words []string
+
+	for _, t := range tokens {
+		if t.kind == tokenOperator {
+			if len(words) > 0 {
+				commands = append(commands, words)
+			}
+			words = nil
+			continue
+		}
+		words = append(words, t.value)
+	}
+	if len(words) > 0 {
+		commands = append(commands, words)
+	}
+
+	return commands
+}
+
+// isAssignment reports whether the word is a NAME=value variable assignment
+func isAssignment(word string) bool {
+	name, _, found := strings.Cut(word, "=")
+	if !found || name == "" {
+		return false
+	}
+	for i, ch := range name {
+		if ch != '_' && !(ch >= 'a' && ch <= 'z') && !(ch >= 'A' && ch <= 'Z') && !(i > 0 && ch >= '0' && ch <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// skipFlags returns the index of the first word that is not a flag, skipping the values of flags in argFlags
+func skipFlags(words []string, argFlags map[string]bool) int {
+	i := 0
+	for i < len(words) {
+		word := words[i]
+		if word == "--" {
+			return i + 1
+		}
+		if len(word) < 2 || word[0] != '-' {
+			return i
+		}
+		name, _, hasValue := strings.Cut(word, "=")
+		i++
+		if argFlags[name] && !hasValue {
+			i++
+		}
+	}
+	return i
+}
+
+// toolName strips the path from the executable, e.g. /usr/local/bin/go becomes go
+func toolName(word string) string {
+	return word[strings.LastIndex(word, "/")+1:]
+}
+
+// unwrap strips variable assignments and wrapper commands, returning the words of the executed command
+func unwrap(words []string) []string {
+	for len(words) > 0 {
+		if isAssignment(words[0]) {
+			words = words[1:]
+			continue
+		}
+
+		w, ok := wrappers[toolName(words[0])]
+		if !ok {
+			return words
+		}
+		words = words[1:]
+
+		words = words[skipFlags(words, w.argFlags):]
+		if w.assignments {
+			for len(words) > 0 && isAssignment(words[0]) {
+				words = words[1:]
+			}
+		}
+		words = words[min(w.positional, len(words)):]
+	}
+
+	return words
+}
+
+// categorize returns the tool and its subcommand of a single simple command
+func categorize(words []string) (string, string) {
+	words = unwrap(words)
+	if len(words) == 0 {
+		return "", ""
+	}
+
+	tool := toolName(words[0])
+
+	argFlags, ok := subcommandTools[tool]
+	if !ok {
+		return tool, ""
+	}
+
+	args := words[1:]
+	if i := skipFlags(args, argFlags); i < len(args) {
+		return tool, args[i]
+	}
+
+	return tool, ""
+}
+
+// CategorizeCommand returns the category, the tool that is executed, and the subcategory, the tool's
+// subcommand if it has one, of a command line. Wrappers like sudo, env or time are unwrapped, and for
+// pipelines and chains the first command that does more than changing the shell state is used.
+func CategorizeCommand(command string) (string, string) {
+	var category, subcategory string
+
+	for _, words := range splitCommands(tokenize(command)) {
+		tool, sub := categorize(words)
+		if tool == "" {
+			continue
+		}
+		if !bookkeeping[tool] {
+			return tool, sub
+		}
+		if category == "" {
+			category, subcategory = tool, sub
+		}
+	}
+
+	return category, subcategory
+}
+
+// ParseCommand extracts the command name from a command string.
+func ParseCommand(command string) string {
+	category, _ := CategorizeCommand(command)
+	return category
+}
@@ -0,0 +1,66 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategorizeCommand(t *testing.T) {
+	testCases := []struct {
+		command     string
+		category    string
+		subcategory string
+	}{
+		{"env FOO=1 make", "make", ""},
+		{"FOO=1 BAR=2 go test ./...", "go", "test"},
+		{"time go test ./...", "go", "test"},
+		{"sudo -u bob kubectl get pods", "kubectl", "get"},
+		{"sudo --user=bob -E kubectl -n prod describe pod", "kubectl", "describe"},
+		{"watch -n 5 kubectl get pods", "kubectl", "get"},
+		{"find . -name '*.go' | xargs -I {} gofmt -l {}", "find", ""},
+		{"timeout -s KILL 10s cargo build --release", "cargo", "build"},
+		{"nice -n 10 nohup ./server", "server", ""},
+		{"cd api && make build", "make", ""},
+		{"export FOO=1; git -C repo commit -m 'a | b && c'", "git", "commit"},
+		{"git commit -m \"fix; again\" && git push", "git", "commit"},
+		{"ps aux | grep foo", "ps", ""},
+		{"cd api >/dev/null 2>&1 && go test ./...", "go", "test"},
+		{"echo done >&2; git push", "git", "push"},
+		{"cd api &>/dev/null && cargo build", "cargo", "build"},
+		{"cd api &>>build.log && make", "make", ""},
+		{"cd api 0<&3 && npm ci", "npm", "ci"},
+		{"docker compose up -d & sleep 1", "docker", "compose"},
+		{"/usr/local/bin/go build", "go", "build"},
+		{"echo hello", "echo", ""},
+		{"cd", "cd", ""},
+		{"  # just a comment", "", ""},
+		{"git status # check", "git", "status"},
+		{"echo 'unterminated", "echo", ""},
+		{"", "", ""},
+	}
+
+	for _, tc := range testCases {
+		category, subcategory := CategorizeCommand(tc.command)
+		assert.Equal(t, tc.category, category, tc.command)
+		assert.Equal(t, tc.subcategory, subcategory, tc.command)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tokens := tokenize(`FOO="a b" cmd 'c|d' e\ f|&g||h;i`)
+
+	var values []string
+	for _, tok := range tokens {
+		values = append(values, tok.value)
+	}
+
+	assert.Equal(t, []string{"FOO=a b", "cmd", "c|d", "e f", "|&", "g", "||", "h", ";", "i"}, values)
+
+	values = nil
+	for _, tok := range tokenize(`make 2>&1 >&2 &>log&>>log <&3 3>&- & y`) {
+		values = append(values, tok.value)
+	}
+
+	assert.Equal(t, []string{"make", "2>&1", ">&2", "&>log", "&>>log", "<&3", "3>&-", "&", "y"}, values)
+}
@@ -0,0 +1,223 @@
+package collector
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/devzero-inc/oda/database"
+	"github.com/devzero-inc/oda/logging"
+)
+
+const (
+	// PauseKindPause is a pause of the whole collection of commands, started with oda pause
+	PauseKindPause = "pause"
+	// PauseKindIncognito is a shell scoped pause, started with oda incognito
+	PauseKindIncognito = "incognito"
+)
+
+// PauseWindow is the model for a period in which commands were intentionally not recorded
+type PauseWindow struct {
+	Id   int64  `json:"id" db:"id"`
+	UUID string `json:"uuid" db:"uuid"`
+	Kind string `json:"kind" db:"kind"`
+	// StartTime is the time the pause started in milliseconds
+	StartTime int64 `json:"start_time" db:"start_time"`
+	// EndTime is the time the pause ended in milliseconds, 0 while the pause is active
+	EndTime int64 `json:"end_time" db:"end_time"`
+	// Until is the time the pause expires in milliseconds, 0 for a pause until resumed
+	Until int64 `json:"until" db:"until"`
+}
+
+// pauseState is the state of the global pause, guarded by commandsMutex
+type pauseState struct {
+	// window is the active global pause, nil when commands are recorded
+	window *PauseWindow
+	// stored is the copy of window owned by the writer, which sets its ID once it is inserted,
+	// the event loop only hands it over to the writes ending or changing the pause
+	stored *PauseWindow
+}
+
+// InsertPauseWindow inserts a pause window into the database and sets its ID
+func InsertPauseWindow(window *PauseWindow) error {
+	query := `INSERT INTO pause_windows (uuid, kind, start_time, end_time, until)
+	VALUES (:uuid, :kind, :start_time, :end_time, :until)`
+
+	result, err := database.DB.NamedExec(query, window)
+	if err != nil {
+		return err
+	}
+
+	window.Id, err = result.LastInsertId()
+
+	return err
+}
+
+// EndPauseWindow sets the end time of the pause window
+func EndPauseWindow(id int64, endTime int64) error {
+	_, err := database.DB.Exec("UPDATE pause_windows SET end_time = ? WHERE id = ?", endTime, id)
+
+	return err
+}
+
+// GetActivePauseWindows fetches the pause windows that have not ended yet
+func GetActivePauseWindows() ([]PauseWindow, error) {
+	var windows []PauseWindow
+
+	query := `SELECT id, uuid, kind, start_time, end_time, until FROM pause_windows WHERE end_time = 0 ORDER BY start_time ASC`
+
+	if err := database.DB.Select(&windows, query); err != nil {
+		logging.Log.Err(err).Msg("Failed to get active pause windows")
+		return nil, err
+	}
+
+	return windows, nil
+}
+
+// GetPauseWindowsForPeriod fetches the pause windows overlapping the given period
+func GetPauseWindowsForPeriod(start int64, end int64) ([]PauseWindow, error) {
+	var windows []PauseWindow
+
+	query := `SELECT id, uuid, kind, start_time, end_time, until
+              FROM pause_windows
+              WHERE start_time <= ? AND (end_time = 0 OR end_time >= ?)
+              ORDER BY start_time ASC;`
+
+	if err := database.DB.Select(&windows, query, end, start); err != nil {
+		logging.Log.Err(err).Msg("Failed to get pause windows for period")
+		return nil, err
+	}
+
+	return windows, nil
+}
+
+// handlePause starts a pause window. A global pause stops recording of new commands until it is resumed
+// or expires, incognito windows are only recorded as the shell hooks already stay silent.
+func (c *Collector) handlePause(msg *Message, now time.Time) error {
+	window := &PauseWindow{
+		UUID:      msg.UUID,
+		Kind:      msg.Kind,
+		StartTime: now.UnixMilli(),
+	}
+	if window.Kind == "" {
+		window.Kind = PauseKindPause
+	}
+	if window.Kind != PauseKindPause && window.Kind != PauseKindIncognito {
+		return fmt.Errorf("invalid pause kind: %s", window.Kind)
+	}
+
+	if duration, ok := parseShellDuration(msg.Duration); ok && duration > 0 {
+		window.Until = now.Add(duration).UnixMilli()
+	}
+
+	if window.Kind == PauseKindIncognito {
+		c.persist("insert pause window", func() error {
+			return InsertPauseWindow(window)
+		})
+		return nil
+	}
+
+	c.collectionConfig.commandsMutex.Lock()
+	defer c.collectionConfig.commandsMutex.Unlock()
+
+	// Pausing while paused only extends or shortens the active pause
+	if active := c.collectionConfig.pause.window; active != nil {
+		active.Until = window.Until
+		stored, until := c.collectionConfig.pause.stored, window.Until
+		c.persist("change pause expiry", func() error {
+			_, err := database.DB.Exec("UPDATE pause_windows SET until = ? WHERE id = ?", until, stored.Id)
+			return err
+		})
+		return nil
+	}
+
+	stored := *window
+	c.persist("insert pause window", func() error {
+		return InsertPauseWindow(&stored)
+	})
+	c.collectionConfig.pause.window = window
+	c.collectionConfig.pause.stored = &stored
+
+	c.logger.Info().Msg("Recording of commands paused")
+
+	return nil
+}
+
+// handleResume ends the pause window with the UUID of the message, or the active global pause
+func (c *Collector) handleResume(msg *Message, receivedAt time.Time) error {
+	now := receivedAt.UnixMilli()
+
+	c.collectionConfig.commandsMutex.Lock()
+	defer c.collectionConfig.commandsMutex.Unlock()
+
+	active := c.collectionConfig.pause.window
+	if msg.UUID != "" && (active == nil || active.UUID != msg.UUID) {
+		c.persist("end pause window", func() error {
+			_, err := database.DB.Exec("UPDATE pause_windows SET end_time = ? WHERE uuid = ? AND end_time = 0", now, msg.UUID)
+			return err
+		})
+		return nil
+	}
+
+	if active == nil {
+		return nil
+	}
+
+	c.endPause(now)
+
+	c.logger.Info().Msg("Recording of commands resumed")
+
+	return nil
+}
+
+// isPaused reports whether recording of commands is paused at the given time,
+// an expired pause is ended at its expiry time. commandsMutex has to be held by the caller.
+func (c *Collector) isPaused(now time.Time) bool {
+	active := c.collectionConfig.pause.window
+	if active == nil {
+		return false
+	}
+
+	if active.Until == 0 || now.UnixMilli() < active.Until {
+		return true
+	}
+
+	c.endPause(active.Until)
+
+	return false
+}
+
+// endPause clears the active global pause and queues ending its window at the given time in milliseconds.
+// commandsMutex has to be held by the caller.
+func (c *Collector) endPause(endTime int64) {
+	stored := c.collectionConfig.pause.stored
+	c.persist("end pause window", func() error {
+		return EndPauseWindow(stored.Id, endTime)
+	})
+	c.collectionConfig.pause.window = nil
+	c.collectionConfig.pause.stored = nil
+}
+
+// restorePause loads the global pause that was active when the collector was stopped
+func (c *Collector) restorePause() {
+	windows, err := GetActivePauseWindows()
+	if err != nil {
+		c.logger.Error().Err(err).Msg("Failed to restore pause")
+		return
+	}
+
+	c.collectionConfig.commandsMutex.Lock()
+	defer c.collectionConfig.commandsMutex.Unlock()
+
+	for i := range windows {
+		if windows[i].Kind != PauseKindPause {
+			continue
+		}
+		stored := windows[i]
+		c.collectionConfig.pause.window = &windows[i]
+		c.collectionConfig.pause.stored = &stored
+		if c.isPaused(time.Now()) {
+			c.logger.Info().Msg("Restored pause of command recording")
+		}
+		return
+	}
+}
@@ -0,0 +1,109 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPauseAndResume(t *testing.T) {
+	setupTestDatabase(t)
+
+	c := NewCollector("", nil, zerolog.Nop(), IntervalConfig{}, ProcessingConfig{}, AuthConfig{}, &fakeProcess{}, nil)
+	c.collectionConfig.isCollectionRunning = true
+	c.collectionConfig.collectionCancelFunc = func() {}
+
+	assert.NoError(t, c.handleStartCommand(&Message{Phase: PhaseStart, Command: "make build", UUID: "before", Directory: t.TempDir()}, nil, time.Now()))
+	assert.NoError(t, c.handlePause(&Message{Phase: PhasePause}, time.Now()))
+
+	// Commands started while paused are not recorded, commands started before still finish
+	assert.NoError(t, c.handleStartCommand(&Message{Phase: PhaseStart, Command: "ssh prod", UUID: "during", Directory: t.TempDir()}, nil, time.Now()))
+	assert.NotContains(t, c.collectionConfig.ongoingCommands, "during")
+	assert.NoError(t, c.handleEndCommand(&Message{Phase: PhaseEnd, UUID: "during"}, time.Now()))
+	assert.NoError(t, c.handleEndCommand(&Message{Phase: PhaseEnd, UUID: "before", Result: "success"}, time.Now()))
+	runWrites(t, c)
+
+	windows, err := GetActivePauseWindows()
+	assert.NoError(t, err)
+	assert.Len(t, windows, 1)
+	assert.Equal(t, PauseKindPause, windows[0].Kind)
+
+	assert.NoError(t, c.handleResume(&Message{Phase: PhaseResume}, time.Now()))
+	assert.Nil(t, c.collectionConfig.pause.window)
+	runWrites(t, c)
+
+	windows, err = GetActivePauseWindows()
+	assert.NoError(t, err)
+	assert.Empty(t, windows)
+
+	assert.NoError(t, c.handleStartCommand(&Message{Phase: PhaseStart, Command: "make test", UUID: "after", Directory: t.TempDir()}, nil, time.Now()))
+	assert.Contains(t, c.collectionConfig.ongoingCommands, "after")
+}
+
+func TestPauseExpires(t *testing.T) {
+	setupTestDatabase(t)
+
+	c := NewCollector("", nil, zerolog.Nop(), IntervalConfig{}, ProcessingConfig{}, AuthConfig{}, &fakeProcess{}, nil)
+	c.collectionConfig.isCollectionRunning = true
+
+	assert.NoError(t, c.handlePause(&Message{Phase: PhasePause, Duration: "60000"}, time.Now()))
+	until := c.collectionConfig.pause.window.Until
+
+	assert.True(t, c.isPaused(time.UnixMilli(until-1)))
+	assert.False(t, c.isPaused(time.UnixMilli(until)))
+	runWrites(t, c)
+
+	windows, err := GetPauseWindowsForPeriod(0, until)
+	assert.NoError(t, err)
+	assert.Len(t, windows, 1)
+	assert.Equal(t, until, windows[0].EndTime)
+}
+
+func TestEndAfterPauseExpired(t *testing.T) {
+	setupTestDatabase(t)
+
+	c := NewCollector("", nil, zerolog.Nop(), IntervalConfig{}, ProcessingConfig{}, AuthConfig{}, &fakeProcess{}, nil)
+
+	now := time.Now()
+	assert.NoError(t, c.handlePause(&Message{Phase: PhasePause, Duration: "60000"}, now))
+
+	// Once the pause expired, an unmatched end is an error again and the pause is ended
+	assert.Error(t, c.handleEndCommand(&Message{Phase: PhaseEnd, UUID: "unknown"}, now.Add(2*time.Minute)))
+	assert.Nil(t, c.collectionConfig.pause.window)
+}
+
+func TestIncognitoWindow(t *testing.T) {
+	setupTestDatabase(t)
+
+	c := NewCollector("", nil, zerolog.Nop(), IntervalConfig{}, ProcessingConfig{}, AuthConfig{}, &fakeProcess{}, nil)
+
+	assert.NoError(t, c.handlePause(&Message{Phase: PhasePause, Kind: PauseKindIncognito, UUID: "shell"}, time.Now()))
+	// Incognito is scoped to a single shell, recording continues everywhere else
+	assert.Nil(t, c.collectionConfig.pause.window)
+
+	assert.Error(t, c.handlePause(&Message{Phase: PhasePause, Kind: "unknown"}, time.Now()))
+
+	assert.NoError(t, c.handleResume(&Message{Phase: PhaseResume, Kind: PauseKindIncognito, UUID: "shell"}, time.Now()))
+	runWrites(t, c)
+
+	windows, err := GetPauseWindowsForPeriod(0, time.Now().UnixMilli())
+	assert.NoError(t, err)
+	assert.Len(t, windows, 1)
+	assert.Equal(t, PauseKindIncognito, windows[0].Kind)
+	assert.NotZero(t, windows[0].EndTime)
+}
+
+func TestRestorePause(t *testing.T) {
+	setupTestDatabase(t)
+
+	assert.NoError(t, InsertPauseWindow(&PauseWindow{Kind: PauseKindIncognito, StartTime: 1}))
+	assert.NoError(t, InsertPauseWindow(&PauseWindow{Kind: PauseKindPause, StartTime: 2}))
+
+	c := NewCollector("", nil, zerolog.Nop(), IntervalConfig{}, ProcessingConfig{}, AuthConfig{}, &fakeProcess{}, nil)
+	c.restorePause()
+
+	assert.NotNil(t, c.collectionConfig.pause.window)
+	assert.Equal(t, int64(2), c.collectionConfig.pause.window.StartTime)
+}
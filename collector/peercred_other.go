@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package collector
+
+import "net"
+
+// peerUID is not supported on this platform, the socket permissions are relied on instead
+func peerUID(_ net.Conn) (uint32, error) {
+	return 0, errPeerCredentialsUnsupported
+}
@@ -0,0 +1,224 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/devzero-inc/oda/util"
+)
+
+const (
+	// eventQueueSize is the number of received messages that can wait for the event loop
+	eventQueueSize = 1024
+	// enqueueTimeout is the maximum time a socket handler waits for space in the event queue
+	enqueueTimeout = 5 * time.Second
+	// writeQueueSize is the number of database writes that can wait for the writer
+	writeQueueSize = 1024
+	// messageReadTimeout is the maximum time a client has to send its message after connecting
+	messageReadTimeout = 2 * time.Second
+)
+
+// event is a message received on the socket, waiting to be handled by the event loop
+type event struct {
+	msg *Message
+	// receivedAt is the time the message was received, so the time spent in the queue does not skew the command times
+	receivedAt time.Time
+	// git is the Git context of the directory of a start message, resolved by the socket handler as it runs git.
+	// It is nil if the directory is not in a Git repository.
+	git *util.GitContext
+}
+
+// ordering keeps the events in the order their connections were accepted. The Git context of a start is resolved
+// before it is queued, so without it the end of a fast command could overtake its start and be left unmatched.
+type ordering struct {
+	mutex sync.Mutex
+	// next is the sequence number of the next accepted connection, released of the next one to queue
+	next     uint64
+	released uint64
+	// ready are the handled connections waiting for an earlier one, nil for connections without an event
+	ready map[uint64]*event
+}
+
+// write is a database write handed over from the event loop to the writer
+type write struct {
+	// description is what the write does, used in the error message if it fails
+	description string
+	run         func() error
+}
+
+// isKnownPhase reports whether the event loop can handle messages of the phase
+func isKnownPhase(phase string) bool {
+	switch phase {
+	case PhaseStart, PhaseEnd, PhaseSessionStart, PhaseSessionEnd, PhasePause, PhaseResume:
+		return true
+	default:
+		return false
+	}
+}
+
+// enqueue hands the event over to the event loop, socket handlers never touch the command state themselves
+func (c *Collector) enqueue(ev event) error {
+	select {
+	case c.collectionConfig.events <- ev:
+		return nil
+	case <-time.After(enqueueTimeout):
+		return fmt.Errorf("event queue is full, dropping %s message for command %s", ev.msg.Phase, ev.msg.UUID)
+	}
+}
+
+// acceptSequence returns the sequence number of a newly accepted connection
+func (c *Collector) acceptSequence() uint64 {
+	o := &c.collectionConfig.ordering
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	seq := o.next
+	o.next++
+
+	return seq
+}
+
+// release queues the event of the connection with the sequence number together with the events of later connections
+// that were waiting for it, a nil event marks a connection without an event, e.g. one with an invalid message
+func (c *Collector) release(seq uint64, ev *event) {
+	o := &c.collectionConfig.ordering
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if o.ready == nil {
+		o.ready = make(map[uint64]*event)
+	}
+	o.ready[seq] = ev
+
+	for {
+		next, ok := o.ready[o.released]
+		if !ok {
+			return
+		}
+		delete(o.ready, o.released)
+		o.released++
+
+		if next == nil {
+			continue
+		}
+		// The events are queued while holding the mutex, so two handlers can not queue them out of order
+		if err := c.enqueue(*next); err != nil {
+			c.logger.Error().Err(err).Msg("Failed to queue event")
+		}
+	}
+}
+
+// persist queues a database write for the writer, so the event loop only changes the in-memory state.
+// Writes run in the order they are queued, e.g. an ongoing command is always inserted before it is deleted.
+func (c *Collector) persist(description string, run func() error) {
+	c.collectionConfig.writes <- write{description: description, run: run}
+}
+
+// runWriter runs the queued database writes until the event loop closes the queue
+func (c *Collector) runWriter() {
+	for w := range c.collectionConfig.writes {
+		if err := w.run(); err != nil {
+			c.logger.Error().Err(err).Msgf("Failed to %s", w.description)
+		}
+	}
+	c.logger.Debug().Msg("Shutting down database writer")
+}
+
+// processEvents is the event loop, the only goroutine changing the state of the commands, sessions and pauses.
+// Abandoned commands are reaped on the same loop, so they can not race with their end message.
+// The database writes are queued for the writer, which stops once the loop has handled the remaining events.
+func (c *Collector) processEvents(ctx context.Context) {
+	defer close(c.collectionConfig.writes)
+
+	var reap <-chan time.Time
+	if c.intervalConfig.ReaperInterval > 0 {
+		ticker := time.NewTicker(c.intervalConfig.ReaperInterval)
+		defer ticker.Stop()
+		reap = ticker.C
+	} else {
+		c.logger.Debug().Msg("Reaper for abandoned commands is disabled")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.drainEvents()
+			c.logger.Debug().Msg("Shutting down event loop")
+			return
+		case ev := <-c.collectionConfig.events:
+			c.handleEvent(ev)
+		case now := <-reap:
+			c.reapOnce(now)
+		}
+	}
+}
+
+// drainEvents handles the events that were already received when the event loop is stopped
+func (c *Collector) drainEvents() {
+	for {
+		select {
+		case ev := <-c.collectionConfig.events:
+			c.handleEvent(ev)
+		default:
+			return
+		}
+	}
+}
+
+// handleEvent dispatches the event to the handler of its phase
+func (c *Collector) handleEvent(ev event) {
+	msg := ev.msg
+
+	switch msg.Phase {
+	case PhaseStart:
+		if err := c.handleStartCommand(msg, ev.git, ev.receivedAt); err != nil {
+			c.logger.Error().Err(err).Msg("Error handling start command")
+		}
+	case PhaseEnd:
+		if err := c.handleEndCommand(msg, ev.receivedAt); err != nil {
+			c.logger.Error().Err(err).Msg("Error handling end command")
+		}
+	case PhaseSessionStart:
+		if err := c.handleSessionStart(msg, ev.receivedAt); err != nil {
+			c.logger.Error().Err(err).Msg("Error handling session start")
+		}
+	case PhaseSessionEnd:
+		if err := c.handleSessionEnd(msg, ev.receivedAt); err != nil {
+			c.logger.Error().Err(err).Msg("Error handling session end")
+		}
+	case PhasePause:
+		if err := c.handlePause(msg, ev.receivedAt); err != nil {
+			c.logger.Error().Err(err).Msg("Error handling pause")
+		}
+	case PhaseResume:
+		if err := c.handleResume(msg, ev.receivedAt); err != nil {
+			c.logger.Error().Err(err).Msg("Error handling resume")
+		}
+	}
+}
+
+// requestSample asks the sampler for a process snapshot. Requests made while a snapshot is
+// already pending are coalesced, so a burst of commands results in a single snapshot.
+func (c *Collector) requestSample() {
+	select {
+	case c.collectionConfig.sampleRequests <- struct{}{}:
+	default:
+	}
+}
+
+// runSampler takes the requested process snapshots, one at a time
+func (c *Collector) runSampler(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			c.logger.Debug().Msg("Shutting down process sampler")
+			return
+		case <-c.collectionConfig.sampleRequests:
+			if err := c.collectOnce(); err != nil {
+				c.logger.Error().Err(err).Msg("Failed to collect system information")
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devzero-inc/oda/database"
+	"github.com/devzero-inc/oda/util"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrentStartsAndEnds(t *testing.T) {
+	setupTestDatabase(t)
+
+	intervals := IntervalConfig{
+		CommandInterval:           10 * time.Millisecond,
+		CommandIntervalMultiplier: 2,
+		MaxDuration:               time.Minute,
+	}
+	c := NewCollector("", nil, zerolog.Nop(), intervals, ProcessingConfig{}, AuthConfig{}, &fakeProcess{}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		c.processEvents(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		c.runWriter()
+	}()
+	go func() {
+		defer wg.Done()
+		c.runSampler(ctx)
+	}()
+
+	const commands = 50
+	var senders sync.WaitGroup
+	for i := 0; i < commands; i++ {
+		senders.Add(1)
+		go func(i int) {
+			defer senders.Done()
+			uuid := fmt.Sprintf("command-%d", i)
+			// The end is sent right after the start, the event loop keeps the order of a single sender
+			assert.NoError(t, c.enqueue(event{msg: &Message{Phase: PhaseStart, UUID: uuid, Command: "make", PID: int64(i + 1)}, receivedAt: time.Now()}))
+			assert.NoError(t, c.enqueue(event{msg: &Message{Phase: PhaseEnd, UUID: uuid, Result: "success"}, receivedAt: time.Now()}))
+		}(i)
+	}
+
+	// Messages from the socket go through the same queue
+	client, server := net.Pipe()
+	go func() {
+		defer client.Close()
+		_ = EncodeMessage(client, &Message{Phase: PhaseStart, UUID: "socket", Command: "go test", PID: 100})
+	}()
+	assert.NoError(t, c.handleSocketCollection(server, c.acceptSequence()))
+
+	senders.Wait()
+
+	client, server = net.Pipe()
+	go func() {
+		defer client.Close()
+		_ = EncodeMessage(client, &Message{Phase: PhaseEnd, UUID: "socket", Result: "success"})
+	}()
+	assert.NoError(t, c.handleSocketCollection(server, c.acceptSequence()))
+
+	cancel()
+	wg.Wait()
+
+	var stored int
+	assert.NoError(t, database.DB.Get(&stored, "SELECT COUNT(*) FROM commands WHERE end_time != 0"))
+	assert.Equal(t, commands+1, stored)
+
+	c.collectionConfig.commandsMutex.Lock()
+	assert.Empty(t, c.collectionConfig.ongoingCommands)
+	c.collectionConfig.commandsMutex.Unlock()
+	assert.Equal(t, 0, c.collectionConfig.activeCommandsCounter)
+	assert.False(t, c.collectionConfig.isCollectionRunning)
+}
+
+func TestHandleSocketCollectionResolvesGitContext(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644))
+
+	c := NewCollector("", nil, zerolog.Nop(), IntervalConfig{}, ProcessingConfig{}, AuthConfig{}, &fakeProcess{}, nil)
+
+	client, server := net.Pipe()
+	go func() {
+		defer client.Close()
+		_ = EncodeMessage(client, &Message{Phase: PhaseStart, UUID: "1", Command: "make", Directory: root})
+	}()
+	assert.NoError(t, c.handleSocketCollection(server, c.acceptSequence()))
+
+	// The event loop receives the Git context with the event, it does not run git itself
+	if assert.Len(t, c.collectionConfig.events, 1) {
+		ev := <-c.collectionConfig.events
+		if assert.NotNil(t, ev.git) {
+			assert.Equal(t, "main", ev.git.Branch)
+		}
+	}
+}
+
+func TestSocketKeepsOrderOfSlowStart(t *testing.T) {
+	setupTestDatabase(t)
+
+	c := NewCollector("", nil, zerolog.Nop(), IntervalConfig{MaxDuration: time.Minute}, ProcessingConfig{}, AuthConfig{}, &fakeProcess{}, nil)
+	// Resolving the Git context of a large repository takes longer than the command itself
+	c.resolveGit = func(path string) (*util.GitContext, error) {
+		time.Sleep(100 * time.Millisecond)
+		return &util.GitContext{Branch: "main"}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var loops sync.WaitGroup
+	loops.Add(2)
+	go func() {
+		defer loops.Done()
+		c.processEvents(ctx)
+	}()
+	go func() {
+		defer loops.Done()
+		c.runWriter()
+	}()
+
+	// The shell sends the end right after the start, before the start is handled
+	var handlers sync.WaitGroup
+	for _, msg := range []*Message{
+		{Phase: PhaseStart, UUID: "ls", Command: "ls", Directory: "/src"},
+		{Phase: PhaseEnd, UUID: "ls", Result: "success"},
+	} {
+		seq := c.acceptSequence()
+		client, server := net.Pipe()
+		go func(msg *Message) {
+			defer client.Close()
+			_ = EncodeMessage(client, msg)
+		}(msg)
+
+		handlers.Add(1)
+		go func() {
+			defer handlers.Done()
+			assert.NoError(t, c.handleSocketCollection(server, seq))
+		}()
+	}
+	handlers.Wait()
+
+	cancel()
+	loops.Wait()
+
+	var branch string
+	assert.NoError(t, database.DB.Get(&branch, "SELECT git_branch FROM commands WHERE uuid = 'ls' AND end_time != 0"))
+	assert.Equal(t, "main", branch)
+	assert.Empty(t, c.collectionConfig.ongoingCommands)
+	assert.Equal(t, 0, c.collectionConfig.activeCommandsCounter)
+}
+
+func TestHandleSocketCollectionInvalidPhase(t *testing.T) {
+	c := NewCollector("", nil, zerolog.Nop(), IntervalConfig{}, ProcessingConfig{}, AuthConfig{}, &fakeProcess{}, nil)
+
+	client, server := net.Pipe()
+	go func() {
+		defer client.Close()
+		_ = EncodeMessage(client, &Message{Phase: "unknown", UUID: "1"})
+	}()
+	assert.Error(t, c.handleSocketCollection(server, c.acceptSequence()))
+
+	assert.Empty(t, c.collectionConfig.events)
+}
+
+func TestRequestSampleCoalesces(t *testing.T) {
+	c := NewCollector("", nil, zerolog.Nop(), IntervalConfig{}, ProcessingConfig{}, AuthConfig{}, &fakeProcess{}, nil)
+
+	for i := 0; i < 10; i++ {
+		c.requestSample()
+	}
+
+	assert.Len(t, c.collectionConfig.sampleRequests, 1)
+}
+
+// runWrites runs the database writes queued by the handlers, like the writer does in the background
+func runWrites(t *testing.T, c *Collector) {
+	t.Helper()
+
+	for {
+		select {
+		case w := <-c.collectionConfig.writes:
+			assert.NoError(t, w.run(), "Failed to %s", w.description)
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Wire format of the messages sent by the shell hooks to the collector socket.
+//
+// Version 1 frames start with a single version byte followed by one netstring
+// (`<length>:<bytes>,`) holding the payload. The payload itself is a sequence of
+// key and value netstrings, so every field is explicit, may contain any byte
+// (pipes, quotes, newlines) and is of arbitrary length:
+//
+//	\x01 43:5:phase,5:start,7:command,13:ps aux | grep,
+//
+// Frames that do not start with a known version byte are treated as the legacy
+// pipe separated format: phase|command|directory|user|uuid|pid|result|status
+const (
+	// ProtocolVersion is the current version of the socket wire format
+	ProtocolVersion byte = 1
+	// MaxMessageSize is the maximum size of a single message payload
+	MaxMessageSize = 1 << 20
+	// legacyFieldCount is the number of fields in the legacy pipe separated format
+	legacyFieldCount = 8
+)
+
+const (
+	PhaseStart        = "start"
+	PhaseEnd          = "end"
+	PhasePause        = "pause"
+	PhaseResume       = "resume"
+	PhaseSessionStart = "session_start"
+	PhaseSessionEnd   = "session_end"
+)
+
+// Message is a single event sent by the shell hooks
+type Message struct {
+	Phase     string
+	Command   string
+	Directory string
+	User      string
+	UUID      string
+	PID       int64
+	Result    string
+	Status    string
+	// Timestamp is the time of the event as reported by the shell, seconds since epoch with an optional fraction
+	Timestamp string
+	// Duration is the command duration in milliseconds as reported by the shell, or the length of a pause
+	Duration string
+	// Kind is the kind of a pause, see PauseKindPause and PauseKindIncognito
+	Kind string
+	// Session is the identifier of the shell session, generated by the hooks when the shell starts
+	Session string
+	// TTY is the terminal of the shell session
+	TTY string
+	// Shell is the name of the shell, e.g. bash
+	Shell string
+	// ShellVersion is the version of the shell
+	ShellVersion string
+	// SSH reports whether the shell session is running over SSH
+	SSH bool
+	// TmuxPane is the tmux pane of the shell session, empty outside of tmux
+	TmuxPane string
+}
+
+// fields returns the key value pairs of the message in the order they are encoded
+func (m *Message) fields() [][2]string {
+	return [][2]string{
+		{"phase", m.Phase},
+		{"command", m.Command},
+		{"directory", m.Directory},
+		{"user", m.User},
+		{"uuid", m.UUID},
+		{"pid", strconv.FormatInt(m.PID, 10)},
+		{"result", m.Result},
+		{"status", m.Status},
+		{"timestamp", m.Timestamp},
+		{"duration", m.Duration},
+		{"kind", m.Kind},
+		{"session", m.Session},
+		{"tty", m.TTY},
+		{"shell", m.Shell},
+		{"shell_version", m.ShellVersion},
+		{"ssh", strconv.FormatBool(m.SSH)},
+		{"tmux_pane", m.TmuxPane},
+	}
+}
+
+// setField sets the message field for the given key, unknown keys are ignored
+// so newer hooks can talk to an older collector.
+func (m *Message) setField(key, value string) error {
+	switch key {
+	case "phase":
+		m.Phase = value
+	case "command":
+		m.Command = value
+	case "directory":
+		m.Directory = value
+	case "user":
+		m.User = value
+	case "uuid":
+		m.UUID = value
+	case "pid":
+		if value == "" {
+			return nil
+		}
+		pid, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid pid %q: %w", value, err)
+		}
+		m.PID = pid
+	case "result":
+		m.Result = value
+	case "status":
+		m.Status = value
+	case "timestamp":
+		m.Timestamp = value
+	case "duration":
+		m.Duration = value
+	case "kind":
+		m.Kind = value
+	case "session":
+		m.Session = value
+	case "tty":
+		m.TTY = value
+	case "shell":
+		m.Shell = value
+	case "shell_version":
+		m.ShellVersion = value
+	case "ssh":
+		if value == "" {
+			return nil
+		}
+		ssh, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid ssh %q: %w", value, err)
+		}
+		m.SSH = ssh
+	case "tmux_pane":
+		m.TmuxPane = value
+	}
+
+	return nil
+}
+
+// EncodeMessage writes the message to the writer using the current protocol version
+func EncodeMessage(w io.Writer, msg *Message) error {
+	var payload bytes.Buffer
+	for _, field := range msg.fields() {
+		writeNetstring(&payload, field[0])
+		writeNetstring(&payload, field[1])
+	}
+
+	if payload.Len() > MaxMessageSize {
+		return fmt.Errorf("message too large: %d bytes", payload.Len())
+	}
+
+	var frame bytes.Buffer
+	frame.WriteByte(ProtocolVersion)
+	writeNetstring(&frame, payload.String())
+
+	_, err := w.Write(frame.Bytes())
+	return err
+}
+
+// SendMessage connects to the collector socket and sends a single message
+func SendMessage(socketPath string, msg *Message, timeout time.Duration) error {
+	conn, err := net.DialTimeout("unix", socketPath, timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+
+	return EncodeMessage(conn, msg)
+}
+
+// DecodeMessage reads a single message from the reader, it accepts both the
+// versioned format and the legacy pipe separated format.
+func DecodeMessage(r *bufio.Reader) (*Message, error) {
+	version, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch version[0] {
+	case ProtocolVersion:
+		if _, err := r.ReadByte(); err != nil {
+			return nil, err
+		}
+		return decodeV1(r)
+	default:
+		return decodeLegacy(r)
+	}
+}
+
+// decodeV1 decodes the payload of a version 1 frame
+func decodeV1(r *bufio.Reader) (*Message, error) {
+	payload, err := readNetstring(r, MaxMessageSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid frame: %w", err)
+	}
+
+	msg := &Message{}
+	fields := bufio.NewReader(strings.NewReader(payload))
+	for {
+		key, err := readNetstring(fields, MaxMessageSize)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid field key: %w", err)
+		}
+
+		value, err := readNetstring(fields, MaxMessageSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for field %q: %w", key, err)
+		}
+
+		if err := msg.setField(key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return msg, nil
+}
+
+// decodeLegacy decodes the legacy pipe separated format. Legacy clients send the
+// message without framing, terminated by a newline or by closing the connection,
+// and the message can arrive in several reads. A newline ends the message only once
+// all fields were read, as a multi-line command contains newlines as well.
+// The command is the only field that can legitimately contain pipes, so the
+// fields are anchored from both ends of the message.
+func decodeLegacy(r *bufio.Reader) (*Message, error) {
+	var data string
+	for {
+		line, err := r.ReadString('\n')
+		data += line
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.Count(data, "|") >= legacyFieldCount-1 {
+			break
+		}
+	}
+
+	data = strings.TrimRight(data, "\r\n")
+	parts := strings.Split(data, "|")
+	if len(parts) < legacyFieldCount {
+		return nil, fmt.Errorf("invalid command format")
+	}
+
+	// phase|command...|directory|user|uuid|pid|result|status
+	tail := parts[len(parts)-(legacyFieldCount-2):]
+	msg := &Message{
+		Phase:     parts[0],
+		Command:   strings.Join(parts[1:len(parts)-(legacyFieldCount-2)], "|"),
+		Directory: tail[0],
+		User:      tail[1],
+		UUID:      tail[2],
+		Result:    tail[4],
+		Status:    tail[5],
+	}
+
+	if err := msg.setField("pid", tail[3]); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// writeNetstring writes a single netstring to the buffer
+func writeNetstring(buf *bytes.Buffer, value string) {
+	buf.WriteString(strconv.Itoa(len(value)))
+	buf.WriteByte(':')
+	buf.WriteString(value)
+	buf.WriteByte(',')
+}
+
+// readNetstring reads a single netstring from the reader, returning io.EOF
+// only when there is no more data before the netstring starts.
+func readNetstring(r *bufio.Reader, maxSize int) (string, error) {
+	header, err := r.ReadString(':')
+	if err != nil {
+		if err == io.EOF && header == "" {
+			return "", io.EOF
+		}
+		return "", io.ErrUnexpectedEOF
+	}
+
+	size, err := strconv.Atoi(header[:len(header)-1])
+	if err != nil || size < 0 {
+		return "", fmt.Errorf("invalid length %q", header[:len(header)-1])
+	}
+	if size > maxSize {
+		return "", fmt.Errorf("length %d exceeds maximum of %d", size, maxSize)
+	}
+
+	value := make([]byte, size+1)
+	if _, err := io.ReadFull(r, value); err != nil {
+		return "", io.ErrUnexpectedEOF
+	}
+
+	if value[size] != ',' {
+		return "", fmt.Errorf("missing netstring terminator")
+	}
+
+	return string(value[:size]), nil
+}
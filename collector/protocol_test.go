@@ -0,0 +1,123 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeDecodeMessage(t *testing.T) {
+	testCases := []struct {
+		name string
+		msg  Message
+	}{
+		{"Simple", Message{Phase: PhaseStart, Command: "ls -l", Directory: "/tmp", User: "dev", UUID: "1-2-3", PID: 42}},
+		{"Pipes", Message{Phase: PhaseEnd, Command: "ps aux | grep foo", Directory: "/tmp", UUID: "1-2-3", PID: 42, Result: "success", Status: "0"}},
+		{"Quotes and newlines", Message{Phase: PhaseStart, Command: "echo 'it''s' \"x\"\nprintf '%s,' 1:2", UUID: "id"}},
+		{"Long command", Message{Phase: PhaseStart, Command: strings.Repeat("a", 10_000), UUID: "id"}},
+		{"Session", Message{Phase: PhaseSessionStart, Session: "1-2-3", PID: 42, TTY: "/dev/pts/1", Shell: "bash", ShellVersion: "5.2.15(1)-release", SSH: true, TmuxPane: "%3"}},
+		{"Pause", Message{Phase: PhasePause, Kind: PauseKindIncognito, UUID: "id", Duration: "1800000"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, EncodeMessage(&buf, &tc.msg))
+			assert.Equal(t, ProtocolVersion, buf.Bytes()[0])
+
+			decoded, err := DecodeMessage(bufio.NewReader(&buf))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.msg, *decoded)
+		})
+	}
+}
+
+func TestDecodeMessageFromShell(t *testing.T) {
+	// Frame as produced by the shell hooks, including a field unknown to this version
+	payload := "5:phase,3:end,7:command,13:ps aux | grep,3:pid,2:42,6:result,7:success,6:future,1:x,"
+	frame := "\x01" + "84:" + payload + ","
+
+	msg, err := DecodeMessage(bufio.NewReader(strings.NewReader(frame)))
+	assert.NoError(t, err)
+	assert.Equal(t, &Message{Phase: PhaseEnd, Command: "ps aux | grep", PID: 42, Result: "success"}, msg)
+}
+
+func TestDecodeMessageInvalid(t *testing.T) {
+	testCases := []struct {
+		name  string
+		frame string
+	}{
+		{"Truncated", "\x0150:5:phase,"},
+		{"Bad length", "\x01x:5:phase,"},
+		{"Missing terminator", "\x0116:5:phase,5:start;"},
+		{"Missing value", "\x018:5:phase,,"},
+		{"Bad pid", "\x0112:3:pid,3:abc,,"},
+		{"Legacy too few fields", "start|ls|/tmp\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := DecodeMessage(bufio.NewReader(strings.NewReader(tc.frame)))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDecodeLegacyMessage(t *testing.T) {
+	testCases := []struct {
+		data     string
+		expected Message
+	}{
+		{
+			"start|ls -l|/tmp|dev|1-2-3|42||\n",
+			Message{Phase: PhaseStart, Command: "ls -l", Directory: "/tmp", User: "dev", UUID: "1-2-3", PID: 42},
+		},
+		{
+			"end|ps aux | grep foo|/tmp|dev|1-2-3|42|failure|1\n",
+			Message{Phase: PhaseEnd, Command: "ps aux | grep foo", Directory: "/tmp", User: "dev", UUID: "1-2-3", PID: 42, Result: "failure", Status: "1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		msg, err := DecodeMessage(bufio.NewReader(strings.NewReader(tc.data)))
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, *msg)
+	}
+}
+
+func TestDecodeLegacyMessageInSeveralReads(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected Message
+	}{
+		{
+			"Newline",
+			"end|make|/src|dev|1-2-3|42|success|0\n",
+			Message{Phase: PhaseEnd, Command: "make", Directory: "/src", User: "dev", UUID: "1-2-3", PID: 42, Result: "success", Status: "0"},
+		},
+		{
+			"Closed without newline",
+			"start|make|/src|dev|1-2-3|42||",
+			Message{Phase: PhaseStart, Command: "make", Directory: "/src", User: "dev", UUID: "1-2-3", PID: 42},
+		},
+		{
+			"Multi-line command",
+			"start|for f in *; do\n  echo $f | wc -c\ndone|/src|dev|1-2-3|42||\n",
+			Message{Phase: PhaseStart, Command: "for f in *; do\n  echo $f | wc -c\ndone", Directory: "/src", User: "dev", UUID: "1-2-3", PID: 42},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Every read returns a single byte, like a message split over several writes
+			msg, err := DecodeMessage(bufio.NewReader(iotest.OneByteReader(strings.NewReader(tc.data))))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, *msg)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/devzero-inc/oda/util"
+)
+
+// ResultAbandoned is the result of a command for which the end message never arrived,
+// because the shell was closed, killed or replaced while the command was running.
+const ResultAbandoned = "abandoned"
+
+// reapOnce stores all currently abandoned commands with an abandoned result and ends the abandoned sessions
+func (c *Collector) reapOnce(now time.Time) {
+	for uuid, command := range c.takeAbandonedCommands(now) {
+		command.EndTime = now.UnixMilli()
+		command.ExecutionTime = command.EndTime - command.StartTime
+		command.Result = ResultAbandoned
+
+		c.logger.Debug().Msgf("Reaping abandoned command: %s", command.Command)
+		c.finishCommand(uuid, command)
+	}
+
+	// Finding the abandoned sessions reads the database, so it is left to the writer as well
+	c.persist("reap sessions", func() error {
+		c.reapSessions(now)
+		return nil
+	})
+}
+
+// takeAbandonedCommands removes abandoned commands from ongoing commands and returns them mapped by their UUID
+func (c *Collector) takeAbandonedCommands(now time.Time) map[string]Command {
+	c.collectionConfig.commandsMutex.Lock()
+	defer c.collectionConfig.commandsMutex.Unlock()
+
+	abandoned := make(map[string]Command)
+	for uuid, command := range c.collectionConfig.ongoingCommands {
+		if c.isCommandAlive(command, now) {
+			continue
+		}
+
+		abandoned[uuid] = command
+		delete(c.collectionConfig.ongoingCommands, uuid)
+	}
+
+	return abandoned
+}
+
+// isCommandAlive checks if the command can still receive its end message
+func (c *Collector) isCommandAlive(command Command, now time.Time) bool {
+	if c.intervalConfig.CommandTimeout > 0 &&
+		now.Sub(time.UnixMilli(command.StartTime)) > c.intervalConfig.CommandTimeout {
+		return false
+	}
+
+	// Commands from older hooks might not have the shell PID, only the timeout applies to them
+	if command.PID <= 0 {
+		return true
+	}
+
+	return util.IsProcessRunning(command.PID)
+}
@@ -0,0 +1,37 @@
+package collector
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTakeAbandonedCommands(t *testing.T) {
+	// Spawn and wait for a short-lived process to get a PID that is no longer running
+	cmd := exec.Command("true")
+	assert.NoError(t, cmd.Run())
+	deadPID := int64(cmd.Process.Pid)
+
+	now := time.Now()
+	c := NewCollector("", nil, zerolog.Nop(), IntervalConfig{CommandTimeout: time.Hour}, ProcessingConfig{}, AuthConfig{}, nil, nil)
+	c.collectionConfig.ongoingCommands = map[string]Command{
+		"alive":     {Command: "make", PID: int64(os.Getpid()), StartTime: now.Add(-time.Minute).UnixMilli()},
+		"no-pid":    {Command: "make", StartTime: now.Add(-time.Minute).UnixMilli()},
+		"dead":      {Command: "make", PID: deadPID, StartTime: now.Add(-time.Minute).UnixMilli()},
+		"timed-out": {Command: "make", PID: int64(os.Getpid()), StartTime: now.Add(-2 * time.Hour).UnixMilli()},
+	}
+
+	abandoned := c.takeAbandonedCommands(now)
+
+	assert.Len(t, abandoned, 2)
+	assert.Contains(t, abandoned, "dead")
+	assert.Contains(t, abandoned, "timed-out")
+	assert.Contains(t, c.collectionConfig.ongoingCommands, "alive")
+	assert.Contains(t, c.collectionConfig.ongoingCommands, "no-pid")
+	assert.NotContains(t, c.collectionConfig.ongoingCommands, "dead")
+	assert.NotContains(t, c.collectionConfig.ongoingCommands, "timed-out")
+}
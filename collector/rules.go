@@ -0,0 +1,95 @@
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/devzero-inc/oda/config"
+)
+
+// Rule is a compiled user defined rule to categorize and tag commands
+type Rule struct {
+	command    *regexp.Regexp
+	directory  *regexp.Regexp
+	repository *regexp.Regexp
+	category   string
+	tags       []string
+}
+
+// CompileRules compiles the rules from the configuration
+func CompileRules(rules []config.Rule) ([]Rule, error) {
+	compiled := make([]Rule, 0, len(rules))
+
+	for i, rule := range rules {
+		if rule.Command == "" && rule.Directory == "" && rule.Repository == "" {
+			return nil, fmt.Errorf("rule %d: at least one of command, directory or repository has to be set", i+1)
+		}
+		if rule.Category == "" && len(rule.Tags) == 0 {
+			return nil, fmt.Errorf("rule %d: at least one of category or tags has to be set", i+1)
+		}
+
+		r := Rule{category: rule.Category, tags: rule.Tags}
+
+		var err error
+		if r.command, err = compileOptional(rule.Command); err != nil {
+			return nil, fmt.Errorf("rule %d: invalid command expression: %w", i+1, err)
+		}
+		if r.directory, err = compileOptional(rule.Directory); err != nil {
+			return nil, fmt.Errorf("rule %d: invalid directory expression: %w", i+1, err)
+		}
+		if r.repository, err = compileOptional(rule.Repository); err != nil {
+			return nil, fmt.Errorf("rule %d: invalid repository expression: %w", i+1, err)
+		}
+
+		compiled = append(compiled, r)
+	}
+
+	return compiled, nil
+}
+
+// compileOptional compiles the expression, an empty expression results in a nil regexp
+func compileOptional(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
+
+// matches reports whether all expressions of the rule match the command
+func (r *Rule) matches(command *Command) bool {
+	if r.command != nil && !r.command.MatchString(command.Command) {
+		return false
+	}
+	if r.directory != nil && !r.directory.MatchString(command.Directory) {
+		return false
+	}
+	if r.repository != nil && !r.repository.MatchString(command.RepositoryID) {
+		return false
+	}
+	return true
+}
+
+// applyRules sets the category of the first matching rule with a category and adds the tags of all matching rules
+func applyRules(command *Command, rules []Rule) {
+	categorized := false
+
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.matches(command) {
+			continue
+		}
+
+		if rule.category != "" && !categorized {
+			command.Category = rule.category
+			command.Subcategory = ""
+			categorized = true
+		}
+
+		for _, tag := range rule.tags {
+			if !slices.Contains(command.Tags, tag) {
+				command.Tags = append(command.Tags, tag)
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/devzero-inc/oda/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyRules(t *testing.T) {
+	rules, err := CompileRules([]config.Rule{
+		{Command: "^(make|go build)", Tags: []string{"build"}},
+		{Command: "^kubectl apply", Repository: "^github.com/org/", Category: "deploy", Tags: []string{"deploy"}},
+		{Directory: "^/work", Category: "work", Tags: []string{"build", "work"}},
+	})
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		command  Command
+		category string
+		tags     []string
+	}{
+		{"No match", Command{Command: "ls", Category: "ls", Directory: "/tmp"}, "ls", nil},
+		{"Tag only", Command{Command: "make build", Category: "make", Directory: "/tmp"}, "make", []string{"build"}},
+		{"All expressions match", Command{Command: "kubectl apply -f x", Category: "kubectl", Subcategory: "apply", RepositoryID: "github.com/org/api"}, "deploy", []string{"deploy"}},
+		{"One expression does not match", Command{Command: "kubectl apply -f x", Category: "kubectl", RepositoryID: "github.com/other/api"}, "kubectl", nil},
+		{"Multiple rules", Command{Command: "make", Category: "make", Directory: "/work/api"}, "work", []string{"build", "work"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			applyRules(&tc.command, rules)
+			assert.Equal(t, tc.category, tc.command.Category)
+			assert.Equal(t, tc.tags, tc.command.Tags)
+		})
+	}
+}
+
+func TestCompileRulesInvalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		rule config.Rule
+	}{
+		{"No expression", config.Rule{Tags: []string{"x"}}},
+		{"No category or tags", config.Rule{Command: "x"}},
+		{"Invalid expression", config.Rule{Command: "(", Tags: []string{"x"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := CompileRules([]config.Rule{tc.rule})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package collector
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/devzero-inc/oda/database"
+	"github.com/devzero-inc/oda/logging"
+	"github.com/devzero-inc/oda/util"
+)
+
+// Session is the model for a shell session, registered by the hooks when the shell starts
+type Session struct {
+	Id int64 `json:"id" db:"id"`
+	// UUID is the identifier of the session generated by the hooks
+	UUID string `json:"uuid" db:"uuid"`
+	// PID is the PID of the shell
+	PID  int64  `json:"pid" db:"pid"`
+	User string `json:"user" db:"user"`
+	TTY  string `json:"tty" db:"tty"`
+	// Shell is the name of the shell, e.g. bash
+	Shell        string `json:"shell" db:"shell"`
+	ShellVersion string `json:"shell_version" db:"shell_version"`
+	// SSH reports whether the session is running over SSH
+	SSH bool `json:"ssh" db:"ssh"`
+	// TmuxPane is the tmux pane of the session, empty outside of tmux
+	TmuxPane  string `json:"tmux_pane" db:"tmux_pane"`
+	StartTime int64  `json:"start_time" db:"start_time"`
+	// EndTime is the time the shell exited, 0 while the session is active
+	EndTime int64 `json:"end_time" db:"end_time"`
+	// Commands is the number of commands executed in the session
+	Commands int64 `json:"commands" db:"commands"`
+}
+
+// InsertSession inserts a session into the database, a session that is already registered is left unchanged
+func InsertSession(session Session) error {
+	query := `INSERT OR IGNORE INTO sessions (uuid, pid, user, tty, shell, shell_version, ssh, tmux_pane, start_time, end_time)
+	VALUES (:uuid, :pid, :user, :tty, :shell, :shell_version, :ssh, :tmux_pane, :start_time, :end_time)`
+
+	_, err := database.DB.NamedExec(query, session)
+
+	return err
+}
+
+// EndSession sets the end time of the session, sessions that already ended are left unchanged
+func EndSession(uuid string, endTime int64) error {
+	_, err := database.DB.Exec("UPDATE sessions SET end_time = ? WHERE uuid = ? AND end_time = 0", endTime, uuid)
+
+	return err
+}
+
+// GetSession fetches a session by its UUID
+func GetSession(uuid string) (*Session, error) {
+	var session Session
+
+	query := `SELECT s.*, (SELECT COUNT(*) FROM commands c WHERE c.session_id = s.uuid) AS commands
+              FROM sessions s WHERE s.uuid = ?`
+
+	if err := database.DB.Get(&session, query, uuid); err != nil {
+		logging.Log.Err(err).Msg("Failed to get session")
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// GetActiveSessions fetches the sessions that have not ended yet
+func GetActiveSessions() ([]Session, error) {
+	var sessions []Session
+
+	query := `SELECT *, 0 AS commands FROM sessions WHERE end_time = 0`
+
+	if err := database.DB.Select(&sessions, query); err != nil {
+		logging.Log.Err(err).Msg("Failed to get active sessions")
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// GetSessionsForPeriod fetches the sessions active in the given period, latest first
+func GetSessionsForPeriod(start int64, end int64) ([]Session, error) {
+	var sessions []Session
+
+	query := `SELECT s.*, (SELECT COUNT(*) FROM commands c WHERE c.session_id = s.uuid) AS commands
+              FROM sessions s
+              WHERE s.start_time <= ? AND (s.end_time = 0 OR s.end_time >= ?)
+              ORDER BY s.start_time DESC;`
+
+	if err := database.DB.Select(&sessions, query, end, start); err != nil {
+		logging.Log.Err(err).Msg("Failed to get sessions for period")
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// GetCommandsForSession fetches the commands executed in the session in the order they were started
+func GetCommandsForSession(uuid string) ([]Command, error) {
+	var commands []Command
+
+	query := `SELECT id, category, subcategory, command, directory, execution_time, start_time, end_time, status, result
+              FROM commands
+              WHERE session_id = ?
+              ORDER BY start_time ASC;`
+
+	if err := database.DB.Select(&commands, query, uuid); err != nil {
+		logging.Log.Err(err).Msg("Failed to get commands for session")
+		return nil, err
+	}
+
+	return commands, nil
+}
+
+// DeleteSessionsByDays deletes sessions that ended more than n days ago
+func DeleteSessionsByDays(days int) error {
+	timeToDelete := time.Now().AddDate(0, 0, -days).UnixMilli()
+
+	_, err := database.DB.Exec("DELETE FROM sessions WHERE end_time != 0 AND end_time < ?", timeToDelete)
+
+	return err
+}
+
+// handleSessionStart registers the shell session
+func (c *Collector) handleSessionStart(msg *Message, receivedAt time.Time) error {
+	if msg.Session == "" {
+		return fmt.Errorf("session identifier is missing")
+	}
+
+	session := Session{
+		UUID:         msg.Session,
+		PID:          msg.PID,
+		User:         msg.User,
+		TTY:          msg.TTY,
+		Shell:        msg.Shell,
+		ShellVersion: msg.ShellVersion,
+		SSH:          msg.SSH,
+		TmuxPane:     msg.TmuxPane,
+		StartTime:    receivedAt.UnixMilli(),
+	}
+	if timestamp, ok := parseShellTimestamp(msg.Timestamp); ok {
+		session.StartTime = timestamp.UnixMilli()
+	}
+
+	c.logger.Debug().Msgf("Starting session: %s", session.UUID)
+
+	c.persist("insert session", func() error {
+		return InsertSession(session)
+	})
+
+	return nil
+}
+
+// handleSessionEnd ends the shell session
+func (c *Collector) handleSessionEnd(msg *Message, receivedAt time.Time) error {
+	if msg.Session == "" {
+		return fmt.Errorf("session identifier is missing")
+	}
+
+	endTime := receivedAt.UnixMilli()
+	if timestamp, ok := parseShellTimestamp(msg.Timestamp); ok {
+		endTime = timestamp.UnixMilli()
+	}
+
+	c.logger.Debug().Msgf("Ending session: %s", msg.Session)
+
+	c.persist("end session", func() error {
+		return EndSession(msg.Session, endTime)
+	})
+
+	return nil
+}
+
+// reapSessions ends the sessions whose shell is gone without sending the session end, e.g. a closed terminal
+func (c *Collector) reapSessions(now time.Time) {
+	sessions, err := GetActiveSessions()
+	if err != nil {
+		c.logger.Error().Err(err).Msg("Failed to get active sessions")
+		return
+	}
+
+	for _, session := range sessions {
+		if session.PID <= 0 || util.IsProcessRunning(session.PID) {
+			continue
+		}
+
+		c.logger.Debug().Msgf("Reaping session: %s", session.UUID)
+		if err := EndSession(session.UUID, now.UnixMilli()); err != nil {
+			c.logger.Error().Err(err).Msg("Failed to end session")
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionLifecycle(t *testing.T) {
+	setupTestDatabase(t)
+
+	c := NewCollector("", nil, zerolog.Nop(), IntervalConfig{}, ProcessingConfig{}, AuthConfig{}, &fakeProcess{}, nil)
+
+	assert.Error(t, c.handleSessionStart(&Message{Phase: PhaseSessionStart}, time.Now()))
+
+	assert.NoError(t, c.handleSessionStart(&Message{
+		Phase:        PhaseSessionStart,
+		Session:      "session",
+		PID:          42,
+		User:         "dev",
+		TTY:          "/dev/pts/1",
+		Shell:        "zsh",
+		ShellVersion: "5.9",
+		SSH:          true,
+		TmuxPane:     "%1",
+		Timestamp:    "1700000000.5",
+	}, time.Now()))
+	// A repeated start, e.g. after re-sourcing the hooks, keeps the original session
+	assert.NoError(t, c.handleSessionStart(&Message{Phase: PhaseSessionStart, Session: "session", Timestamp: "1700000100"}, time.Now()))
+	runWrites(t, c)
+
+	assert.NoError(t, InsertCommand(Command{UUID: "2", Category: "build", Command: "make", StartTime: 1700000020000, SessionID: "session"}))
+	assert.NoError(t, InsertCommand(Command{UUID: "1", Category: "vcs", Command: "git pull", StartTime: 1700000010000, SessionID: "session"}))
+	assert.NoError(t, InsertCommand(Command{UUID: "3", Category: "vcs", Command: "git push", StartTime: 1700000030000, SessionID: "other"}))
+
+	session, err := GetSession("session")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1700000000500), session.StartTime)
+	assert.Equal(t, int64(0), session.EndTime)
+	assert.Equal(t, "/dev/pts/1", session.TTY)
+	assert.True(t, session.SSH)
+	assert.Equal(t, int64(2), session.Commands)
+
+	commands, err := GetCommandsForSession("session")
+	assert.NoError(t, err)
+	if assert.Len(t, commands, 2) {
+		assert.Equal(t, "git pull", commands[0].Command)
+		assert.Equal(t, "make", commands[1].Command)
+	}
+
+	assert.NoError(t, c.handleSessionEnd(&Message{Phase: PhaseSessionEnd, Session: "session", Timestamp: "1700000060"}, time.Now()))
+	runWrites(t, c)
+
+	sessions, err := GetSessionsForPeriod(1700000050000, 1700000070000)
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 1) {
+		assert.Equal(t, int64(1700000060000), sessions[0].EndTime)
+	}
+}
+
+func TestReapSessions(t *testing.T) {
+	setupTestDatabase(t)
+
+	c := NewCollector("", nil, zerolog.Nop(), IntervalConfig{}, ProcessingConfig{}, AuthConfig{}, &fakeProcess{}, nil)
+
+	assert.NoError(t, InsertSession(Session{UUID: "gone", PID: 999999999, StartTime: 1}))
+	assert.NoError(t, InsertSession(Session{UUID: "legacy", StartTime: 1}))
+
+	now := time.UnixMilli(1700000000000)
+	c.reapSessions(now)
+
+	gone, err := GetSession("gone")
+	assert.NoError(t, err)
+	assert.Equal(t, now.UnixMilli(), gone.EndTime)
+
+	legacy, err := GetSession("legacy")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), legacy.EndTime)
+}
+
+func TestHandleStartCommandSession(t *testing.T) {
+	setupTestDatabase(t)
+
+	c := NewCollector("", nil, zerolog.Nop(), IntervalConfig{}, ProcessingConfig{}, AuthConfig{}, &fakeProcess{}, nil)
+	c.collectionConfig.isCollectionRunning = true
+
+	assert.NoError(t, c.handleStartCommand(&Message{Phase: PhaseStart, Command: "ls", UUID: "1", Session: "session", Directory: t.TempDir()}, nil, time.Now()))
+	assert.Equal(t, "session", c.collectionConfig.ongoingCommands["1"].SessionID)
+}
@@ -0,0 +1,71 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/devzero-inc/oda/util"
+)
+
+const (
+	// socketDirPermission keeps the socket directory private to the user
+	socketDirPermission = 0700
+	// socketPermission allows only the user to connect to the socket
+	socketPermission = 0600
+	// socketProbeTimeout is the time to wait for an existing collector to accept a connection
+	socketProbeTimeout = 500 * time.Millisecond
+)
+
+// errPeerCredentialsUnsupported is returned on platforms where the peer of a socket can not be identified
+var errPeerCredentialsUnsupported = errors.New("peer credentials are not supported on this platform")
+
+// listen creates the socket with permissions private to the user. A stale socket from a previous run is
+// removed, but a socket that still has a collector listening on it is left alone.
+func listen(socketPath string) (net.Listener, error) {
+	if err := util.Fs.MkdirAll(filepath.Dir(socketPath), socketDirPermission); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	if conn, err := net.DialTimeout("unix", socketPath, socketProbeTimeout); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("collector is already listening on %s", socketPath)
+	}
+
+	if err := util.Fs.RemoveAll(socketPath); err != nil {
+		return nil, fmt.Errorf("failed to clean up existing socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := util.Fs.Chmod(socketPath, socketPermission); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+
+	return listener, nil
+}
+
+// verifyPeer checks that the connection is made by the user running the collector, or root.
+// On platforms without peer credentials the permissions of the socket are relied on.
+func verifyPeer(conn net.Conn) error {
+	uid, err := peerUID(conn)
+	if errors.Is(err, errPeerCredentialsUnsupported) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get peer credentials: %w", err)
+	}
+
+	if uid != uint32(os.Getuid()) && uid != 0 {
+		return fmt.Errorf("connection from user %d rejected", uid)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package collector
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devzero-inc/oda/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListen(t *testing.T) {
+	util.SetupFS()
+
+	socketPath := filepath.Join(t.TempDir(), "oda", "oda.socket")
+
+	listener, err := listen(socketPath)
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	info, err := os.Stat(socketPath)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(socketPermission), info.Mode().Perm())
+
+	dirInfo, err := os.Stat(filepath.Dir(socketPath))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(socketDirPermission), dirInfo.Mode().Perm())
+
+	// A second collector must not take over the socket of a running one
+	_, err = listen(socketPath)
+	assert.Error(t, err)
+}
+
+func TestListenRemovesStaleSocket(t *testing.T) {
+	util.SetupFS()
+
+	socketPath := filepath.Join(t.TempDir(), "oda.socket")
+	assert.NoError(t, os.WriteFile(socketPath, nil, 0600))
+
+	listener, err := listen(socketPath)
+	assert.NoError(t, err)
+	listener.Close()
+}
+
+func TestVerifyPeer(t *testing.T) {
+	util.SetupFS()
+
+	listener, err := listen(filepath.Join(t.TempDir(), "oda.socket"))
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := net.Dial("unix", listener.Addr().String())
+		if err == nil {
+			defer conn.Close()
+		}
+	}()
+
+	conn, err := listener.Accept()
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	assert.NoError(t, verifyPeer(conn))
+}
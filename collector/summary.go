@@ -0,0 +1,30 @@
+package collector
+
+import "github.com/devzero-inc/oda/process"
+
+// summarizeResources aggregates the samples of the command's process tree into the resource summary of the command.
+// Each sample is assumed to represent the usage until the next sample, the last one until the command ended.
+// The interval CPU usage is used, as the lifetime average hides short spikes of long-running processes.
+func summarizeResources(command *Command, samples []*process.Process) {
+	if len(samples) == 0 {
+		return
+	}
+
+	var totalCPU float64
+	for i, sample := range samples {
+		totalCPU += sample.IntervalCPUUsage
+		command.PeakCPUUsage = max(command.PeakCPUUsage, sample.IntervalCPUUsage)
+		command.PeakMemoryUsage = max(command.PeakMemoryUsage, sample.MemoryUsage)
+
+		until := command.EndTime
+		if i+1 < len(samples) {
+			until = samples[i+1].StoredTime
+		}
+		if elapsed := until - sample.StoredTime; elapsed > 0 {
+			// CPU usage is a percentage of a single core, elapsed time is in milliseconds
+			command.CPUSeconds += sample.IntervalCPUUsage / 100 * float64(elapsed) / 1000
+		}
+	}
+
+	command.AvgCPUUsage = totalCPU / float64(len(samples))
+}
@@ -0,0 +1,33 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/devzero-inc/oda/process"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeResources(t *testing.T) {
+	command := Command{StartTime: 0, EndTime: 4_000}
+	samples := []*process.Process{
+		{StoredTime: 1_000, IntervalCPUUsage: 100, MemoryUsage: 2},
+		{StoredTime: 2_000, IntervalCPUUsage: 200, MemoryUsage: 5},
+		{StoredTime: 3_000, IntervalCPUUsage: 0, MemoryUsage: 1},
+	}
+
+	summarizeResources(&command, samples)
+
+	assert.Equal(t, 200.0, command.PeakCPUUsage)
+	assert.Equal(t, 100.0, command.AvgCPUUsage)
+	assert.Equal(t, 3.0, command.CPUSeconds)
+	assert.Equal(t, 5.0, command.PeakMemoryUsage)
+}
+
+func TestSummarizeResourcesWithoutSamples(t *testing.T) {
+	command := Command{StartTime: 0, EndTime: 4_000}
+
+	summarizeResources(&command, nil)
+
+	assert.Equal(t, Command{StartTime: 0, EndTime: 4_000}, command)
+}
@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseShellTimestamp parses a timestamp reported by the shell hooks, like EPOCHREALTIME in bash and zsh,
+// in the form of seconds since epoch with an optional fraction. Depending on the locale the fraction
+// can be separated with a comma instead of a dot.
+func parseShellTimestamp(value string) (time.Time, bool) {
+	value = strings.TrimSpace(strings.Replace(value, ",", ".", 1))
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	seconds, fraction, _ := strings.Cut(value, ".")
+
+	sec, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil || sec <= 0 {
+		return time.Time{}, false
+	}
+
+	var nsec int64
+	if fraction != "" {
+		// Normalize the fraction to nanoseconds
+		if len(fraction) > 9 {
+			fraction = fraction[:9]
+		}
+		fraction += strings.Repeat("0", 9-len(fraction))
+
+		nsec, err = strconv.ParseInt(fraction, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+	}
+
+	return time.Unix(sec, nsec), true
+}
+
+// parseShellDuration parses a duration in milliseconds reported by the shell hooks, like CMD_DURATION in fish
+func parseShellDuration(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ms < 0 {
+		return 0, false
+	}
+
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// applyStartTime sets the start time of the command, preferring the time reported by the shell
+// over the time the start message was received.
+func applyStartTime(command *Command, msg *Message, receivedAt time.Time) {
+	command.StartTime = receivedAt.UnixMilli()
+
+	if startedAt, ok := parseShellTimestamp(msg.Timestamp); ok {
+		command.StartTime = startedAt.UnixMilli()
+		command.StartSkew = receivedAt.Sub(startedAt).Milliseconds()
+	}
+}
+
+// applyEndTime sets the end and execution time of the command, preferring the time reported by the shell,
+// then the duration reported by the shell and the time the end message was received as the last resort.
+func applyEndTime(command *Command, msg *Message, receivedAt time.Time) {
+	command.EndTime = receivedAt.UnixMilli()
+
+	if endedAt, ok := parseShellTimestamp(msg.Timestamp); ok {
+		command.EndTime = endedAt.UnixMilli()
+		command.EndSkew = receivedAt.Sub(endedAt).Milliseconds()
+	} else if duration, ok := parseShellDuration(msg.Duration); ok {
+		command.EndTime = command.StartTime + duration.Milliseconds()
+	}
+
+	command.ExecutionTime = command.EndTime - command.StartTime
+}
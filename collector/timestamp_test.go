@@ -0,0 +1,58 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseShellTimestamp(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected time.Time
+		ok       bool
+	}{
+		{"1700000000.123456", time.Unix(1700000000, 123456000), true},
+		{"1700000000,123456", time.Unix(1700000000, 123456000), true},
+		{"1700000000", time.Unix(1700000000, 0), true},
+		{"1700000000.1234567891", time.Unix(1700000000, 123456789), true},
+		{"", time.Time{}, false},
+		{"abc", time.Time{}, false},
+		{"1700000000.x", time.Time{}, false},
+		{"-1", time.Time{}, false},
+	}
+
+	for _, tc := range testCases {
+		parsed, ok := parseShellTimestamp(tc.value)
+		assert.Equal(t, tc.ok, ok, tc.value)
+		assert.True(t, tc.expected.Equal(parsed), tc.value)
+	}
+}
+
+func TestApplyCommandTimes(t *testing.T) {
+	receivedAt := time.UnixMilli(1700000010_000)
+
+	command := Command{}
+	applyStartTime(&command, &Message{Timestamp: "1700000000.500"}, receivedAt)
+	assert.Equal(t, int64(1700000000_500), command.StartTime)
+	assert.Equal(t, int64(9_500), command.StartSkew)
+
+	applyEndTime(&command, &Message{Timestamp: "1700000005.000"}, receivedAt.Add(time.Second))
+	assert.Equal(t, int64(1700000005_000), command.EndTime)
+	assert.Equal(t, int64(6_000), command.EndSkew)
+	assert.Equal(t, int64(4_500), command.ExecutionTime)
+
+	// Duration is used when the shell does not report timestamps
+	command = Command{}
+	applyStartTime(&command, &Message{}, receivedAt)
+	applyEndTime(&command, &Message{Duration: "1500"}, receivedAt.Add(time.Minute))
+	assert.Equal(t, int64(0), command.StartSkew)
+	assert.Equal(t, int64(1_500), command.ExecutionTime)
+
+	// Receive time is the last resort
+	command = Command{}
+	applyStartTime(&command, &Message{}, receivedAt)
+	applyEndTime(&command, &Message{Duration: "bad"}, receivedAt.Add(time.Second))
+	assert.Equal(t, int64(1_000), command.ExecutionTime)
+}
@@ -8,6 +8,7 @@ import (
 	"os/user"
 	"path/filepath"
 
+	"github.com/devzero-inc/oda/filter"
 	"github.com/devzero-inc/oda/util"
 
 	"github.com/pkg/errors"
@@ -29,6 +30,10 @@ type Config struct {
 	MaxDuration int `mapstructure:"max_duration"`
 	// MaxConcurrentCommands maximum number of concurrent commands to collect - defaults to 20
 	MaxConcurrentCommands int `mapstructure:"max_concurrent_commands"`
+	// ReaperInterval interval in seconds to look for abandoned commands - defaults to 60 seconds
+	ReaperInterval int `mapstructure:"reaper_interval"`
+	// CommandTimeout time in seconds after which a running command is considered abandoned - defaults to 86400 seconds
+	CommandTimeout int `mapstructure:"command_timeout"`
 	// RemoteCollection flag to enable remote collection - defaults to false
 	RemoteCollection bool `mapstructure:"remote_collection"`
 	// ServerAddress host to connect to for remote collection
@@ -37,12 +42,18 @@ type Config struct {
 	SecureConnection bool `mapstructure:"secure_connection"`
 	// CertFile path to the certificate file
 	CertFile string `mapstructure:"cert_file"`
-	// ExcludeRegex regular expression to exclude processes from collection
+	// ExcludeRegex regular expression to exclude processes from collection, kept for compatibility with filters.processes.exclude
 	ExcludeRegex string `mapstructure:"exclude_regex"`
-	// ExcludeCommands regular expression to exclude commands from collection
+	// ExcludeCommands regular expression to exclude commands from collection, kept for compatibility with filters.commands.exclude
 	ExcludeCommands []string `mapstructure:"exclude_commands"`
-	// ProcessCollectionType type of process collection to use, ps or psutil
+	// Filters include and exclude lists for commands, directories, repositories and processes
+	Filters filter.Config `mapstructure:"filters"`
+	// ProcessCollectionType type of process collection to use, ps, psutil or procfs, procfs falls back to ps outside of Linux
 	ProcessCollectionType string `mapstructure:"process_collection_type"`
+	// ProcRoot mount point of the proc filesystem read by the procfs process collection
+	ProcRoot string `mapstructure:"proc_root"`
+	// DiskPath path on the filesystem whose space is recorded in the host metrics
+	DiskPath string `mapstructure:"disk_path"`
 	// TeamID is the team identifier for the workspace
 	TeamID string `mapstructure:"team_id"`
 	// UserID is the user identifier for the workspace
@@ -51,6 +62,25 @@ type Config struct {
 	UserEmail string `mapstructure:"user_email"`
 	// WorkspaceID is the workspace identifier
 	WorkspaceID string `mapstructure:"workspace_id"`
+	// RedactPatterns additional regular expressions for secrets to redact from commands, on top of the built-in detectors
+	RedactPatterns []string `mapstructure:"redact_patterns"`
+	// Rules are user defined rules to categorize and tag commands
+	Rules []Rule `mapstructure:"rules"`
+}
+
+// Rule maps commands matching the regular expressions to a custom category and tags,
+// all non-empty expressions have to match for the rule to apply
+type Rule struct {
+	// Command regular expression matched against the command
+	Command string `mapstructure:"command"`
+	// Directory regular expression matched against the directory where the command is executed
+	Directory string `mapstructure:"directory"`
+	// Repository regular expression matched against the repository identifier, e.g. github.com/org/repo
+	Repository string `mapstructure:"repository"`
+	// Category overrides the category of the matched command
+	Category string `mapstructure:"category"`
+	// Tags are added to the matched command
+	Tags []string `mapstructure:"tags"`
 }
 
 // SystemConfig Configuration that is not available via the configuration file
@@ -102,7 +132,11 @@ func SetupConfig(odaDir string, user *user.User) {
 		CommandIntervalMultiplier: 3,
 		MaxConcurrentCommands:     20,
 		ProcessCollectionType:     "ps",
+		ProcRoot:                  "/proc",
+		DiskPath:                  "/",
 		MaxDuration:               3600,
+		ReaperInterval:            60,
+		CommandTimeout:            86400,
 	}
 
 	if err := viper.ReadInConfig(); err != nil {
@@ -113,5 +147,21 @@ func SetupConfig(odaDir string, user *user.User) {
 		fmt.Fprintf(SysConfig.ErrOut, "Failed to unmarshal config: %s\n", err)
 	}
 
+	if _, err := filter.New(config.FilterConfig()); err != nil {
+		fmt.Fprintf(SysConfig.ErrOut, "Invalid filters in config file: %s\n", err)
+	}
+
 	AppConfig = config
 }
+
+// FilterConfig returns the filters merged with the legacy exclude options
+func (c *Config) FilterConfig() filter.Config {
+	filters := c.Filters
+
+	filters.Commands.Exclude = append(append([]string{}, c.ExcludeCommands...), filters.Commands.Exclude...)
+	if c.ExcludeRegex != "" {
+		filters.Processes.Exclude = append([]string{c.ExcludeRegex}, filters.Processes.Exclude...)
+	}
+
+	return filters
+}
@@ -17,6 +17,9 @@ var (
 	SupportedShells = []string{"/bin/bash", "/bin/zsh", "/bin/fish"}
 )
 
+// SocketName is the file name of the collector socket
+const SocketName = "oda.socket"
+
 func GetShellType(shellLocation string) ShellType {
 	shellType := path.Base(shellLocation)
 	switch shellType {
@@ -108,6 +111,18 @@ func GetOdaDir(homeDir string, user *user.User) (string, error) {
 	return dir, nil
 }
 
+// GetSocketPath returns the path of the collector socket. The socket is placed in the runtime
+// directory of the user when available, so it is private to the user and cleaned up on logout.
+// Installations executed with sudo use the ODA directory, as the runtime directory of the
+// invoking user is not known.
+func GetSocketPath(odaDir string, user *user.User) string {
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" && user == nil {
+		return filepath.Join(runtimeDir, "oda", SocketName)
+	}
+
+	return filepath.Join(odaDir, SocketName)
+}
+
 // GetOdaBinaryPath returns the path to the oda binary
 func GetOdaBinaryPath() (string, error) {
 	exePath, err := os.Executable()
@@ -48,6 +48,7 @@ type Config struct {
 	IsWorkspace         bool
 	ShellTypeToLocation map[config.ShellType]string
 	BaseCommandPath     string
+	SocketPath          string
 }
 
 // Daemon is the service that configures background service
@@ -130,6 +131,10 @@ func (d *Daemon) InstallDaemonConfiguration() error {
 	if d.config.IsWorkspace {
 		collectCmd = append(collectCmd, "-w")
 	}
+	if d.config.SocketPath != "" {
+		// The daemon environment may not have the runtime directory of the user, so the path resolved at install is used
+		collectCmd = append(collectCmd, "--socket", d.config.SocketPath)
+	}
 
 	// create command from args in colllectCmd
 	var command string
@@ -5,6 +5,9 @@ import (
 	"os"
 
 	"github.com/devzero-inc/oda/config"
+	"github.com/devzero-inc/oda/util"
+
+	"github.com/jmoiron/sqlx"
 )
 
 // RunMigrations all additional migrations should be registered here
@@ -15,6 +18,23 @@ func RunMigrations() {
 	createConfigTable()
 	addIndexOnProcesses()
 	shellTypeToLocation()
+	createOngoingCommandsTable()
+	addClockSkewToCommands()
+	addGitContextToCommands()
+	addCommandIdToProcesses()
+	addResourceSummaryToCommands()
+	addSubcategoryToCommands()
+	createCommandTagsTable()
+	createPauseWindowsTable()
+	addSocketPathToConfig()
+	createSessionsTable()
+	addIntervalCPUUsageToProcesses()
+	addResourceMetricsToProcesses()
+	addCmdlineToProcesses()
+	addCgroupToProcesses()
+	createSystemMetricsTable()
+	createProcessRunsTable()
+	normalizeProcesses()
 }
 
 func ensureMigrationTableExists() {
@@ -149,6 +169,493 @@ func shellTypeToLocation() {
 	}
 }
 
+func createOngoingCommandsTable() {
+	migrationName := "create_ongoing_commands_table"
+	if !migrationApplied(migrationName) {
+		// Commands are stored as JSON, so the table does not have to follow every change of the commands table
+		createOngoingCommandsTableSQL := `
+		CREATE TABLE IF NOT EXISTS ongoing_commands (
+			uuid TEXT PRIMARY KEY,
+			command TEXT NOT NULL,
+			stored_time INTEGER
+		);`
+
+		_, err := DB.Exec(createOngoingCommandsTableSQL)
+		if err != nil {
+			fmt.Fprintf(config.SysConfig.ErrOut, "Failed to create ongoing_commands table: %s\n", err)
+			os.Exit(1)
+		}
+		recordMigration(migrationName)
+	}
+}
+
+func addClockSkewToCommands() {
+	migrationName := "add_clock_skew_to_commands"
+	if !migrationApplied(migrationName) {
+		alterSQL := []string{
+			`ALTER TABLE commands ADD COLUMN start_skew INTEGER NOT NULL DEFAULT 0;`,
+			`ALTER TABLE commands ADD COLUMN end_skew INTEGER NOT NULL DEFAULT 0;`,
+		}
+
+		for _, sql := range alterSQL {
+			_, err := DB.Exec(sql)
+			if err != nil {
+				fmt.Fprintf(config.SysConfig.ErrOut, "Failed to add clock skew columns to commands table: %s\n", err)
+				os.Exit(1)
+			}
+		}
+		recordMigration(migrationName)
+	}
+}
+
+func addGitContextToCommands() {
+	migrationName := "add_git_context_to_commands"
+	if !migrationApplied(migrationName) {
+		alterSQL := []string{
+			`ALTER TABLE commands ADD COLUMN git_branch TEXT NOT NULL DEFAULT '';`,
+			`ALTER TABLE commands ADD COLUMN git_commit TEXT NOT NULL DEFAULT '';`,
+			`ALTER TABLE commands ADD COLUMN git_dirty INTEGER;`,
+			`ALTER TABLE commands ADD COLUMN repository_id TEXT NOT NULL DEFAULT '';`,
+			`ALTER TABLE commands ADD COLUMN repository_path TEXT NOT NULL DEFAULT '';`,
+			`CREATE INDEX IF NOT EXISTS idx_commands_repository_id ON commands (repository_id, git_branch);`,
+		}
+
+		for _, sql := range alterSQL {
+			_, err := DB.Exec(sql)
+			if err != nil {
+				fmt.Fprintf(config.SysConfig.ErrOut, "Failed to add git context columns to commands table: %s\n", err)
+				os.Exit(1)
+			}
+		}
+		recordMigration(migrationName)
+	}
+}
+
+func addCommandIdToProcesses() {
+	migrationName := "add_command_id_to_processes"
+	if !migrationApplied(migrationName) {
+		alterSQL := []string{
+			`ALTER TABLE commands ADD COLUMN uuid TEXT NOT NULL DEFAULT '';`,
+			`ALTER TABLE processes ADD COLUMN command_id TEXT NOT NULL DEFAULT '';`,
+			`CREATE INDEX IF NOT EXISTS idx_processes_command_id ON processes (command_id);`,
+		}
+
+		for _, sql := range alterSQL {
+			_, err := DB.Exec(sql)
+			if err != nil {
+				fmt.Fprintf(config.SysConfig.ErrOut, "Failed to add command id to processes table: %s\n", err)
+				os.Exit(1)
+			}
+		}
+		recordMigration(migrationName)
+	}
+}
+
+func addResourceSummaryToCommands() {
+	migrationName := "add_resource_summary_to_commands"
+	if !migrationApplied(migrationName) {
+		alterSQL := []string{
+			`ALTER TABLE commands ADD COLUMN peak_cpu_usage REAL NOT NULL DEFAULT 0;`,
+			`ALTER TABLE commands ADD COLUMN avg_cpu_usage REAL NOT NULL DEFAULT 0;`,
+			`ALTER TABLE commands ADD COLUMN cpu_seconds REAL NOT NULL DEFAULT 0;`,
+			`ALTER TABLE commands ADD COLUMN peak_memory_usage REAL NOT NULL DEFAULT 0;`,
+		}
+
+		for _, sql := range alterSQL {
+			_, err := DB.Exec(sql)
+			if err != nil {
+				fmt.Fprintf(config.SysConfig.ErrOut, "Failed to add resource summary columns to commands table: %s\n", err)
+				os.Exit(1)
+			}
+		}
+		recordMigration(migrationName)
+	}
+}
+
+func addSubcategoryToCommands() {
+	migrationName := "add_subcategory_to_commands"
+	if !migrationApplied(migrationName) {
+		alterSQL := `ALTER TABLE commands ADD COLUMN subcategory TEXT NOT NULL DEFAULT '';`
+
+		_, err := DB.Exec(alterSQL)
+		if err != nil {
+			fmt.Fprintf(config.SysConfig.ErrOut, "Failed to add subcategory column to commands table: %s\n", err)
+			os.Exit(1)
+		}
+		recordMigration(migrationName)
+	}
+}
+
+func createCommandTagsTable() {
+	migrationName := "create_command_tags_table"
+	if !migrationApplied(migrationName) {
+		createCommandTagsTableSQL := `
+		CREATE TABLE IF NOT EXISTS command_tags (
+			command_id INTEGER NOT NULL REFERENCES commands (id) ON DELETE CASCADE,
+			tag TEXT NOT NULL,
+			PRIMARY KEY (command_id, tag)
+		);
+		CREATE INDEX IF NOT EXISTS idx_command_tags_tag ON command_tags (tag);`
+
+		_, err := DB.Exec(createCommandTagsTableSQL)
+		if err != nil {
+			fmt.Fprintf(config.SysConfig.ErrOut, "Failed to create command_tags table: %s\n", err)
+			os.Exit(1)
+		}
+		recordMigration(migrationName)
+	}
+}
+
+func createPauseWindowsTable() {
+	migrationName := "create_pause_windows_table"
+	if !migrationApplied(migrationName) {
+		createPauseWindowsTableSQL := `
+		CREATE TABLE IF NOT EXISTS pause_windows (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			uuid TEXT NOT NULL DEFAULT '',
+			kind TEXT NOT NULL,
+			start_time INTEGER NOT NULL,
+			end_time INTEGER NOT NULL DEFAULT 0,
+			until INTEGER NOT NULL DEFAULT 0
+		);
+		CREATE INDEX IF NOT EXISTS idx_pause_windows_start_time ON pause_windows (start_time);`
+
+		_, err := DB.Exec(createPauseWindowsTableSQL)
+		if err != nil {
+			fmt.Fprintf(config.SysConfig.ErrOut, "Failed to create pause_windows table: %s\n", err)
+			os.Exit(1)
+		}
+		recordMigration(migrationName)
+	}
+}
+
+func addSocketPathToConfig() {
+	migrationName := "add_socket_path_to_config"
+	if !migrationApplied(migrationName) {
+		alterSQL := `ALTER TABLE config ADD COLUMN socket_path TEXT NOT NULL DEFAULT '';`
+
+		_, err := DB.Exec(alterSQL)
+		if err != nil {
+			fmt.Fprintf(config.SysConfig.ErrOut, "Failed to add socket_path column to config table: %s\n", err)
+			os.Exit(1)
+		}
+		recordMigration(migrationName)
+	}
+}
+
+func createSessionsTable() {
+	migrationName := "create_sessions_table"
+	if !migrationApplied(migrationName) {
+		createSessionsTableSQL := `
+		CREATE TABLE IF NOT EXISTS sessions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			uuid TEXT NOT NULL UNIQUE,
+			pid INTEGER NOT NULL DEFAULT 0,
+			user TEXT NOT NULL DEFAULT '',
+			tty TEXT NOT NULL DEFAULT '',
+			shell TEXT NOT NULL DEFAULT '',
+			shell_version TEXT NOT NULL DEFAULT '',
+			ssh INTEGER NOT NULL DEFAULT 0,
+			tmux_pane TEXT NOT NULL DEFAULT '',
+			start_time INTEGER NOT NULL,
+			end_time INTEGER NOT NULL DEFAULT 0
+		);
+		CREATE INDEX IF NOT EXISTS idx_sessions_start_time ON sessions (start_time);
+		ALTER TABLE commands ADD COLUMN session_id TEXT NOT NULL DEFAULT '';
+		CREATE INDEX IF NOT EXISTS idx_commands_session_id ON commands (session_id);`
+
+		_, err := DB.Exec(createSessionsTableSQL)
+		if err != nil {
+			fmt.Fprintf(config.SysConfig.ErrOut, "Failed to create sessions table: %s\n", err)
+			os.Exit(1)
+		}
+		recordMigration(migrationName)
+	}
+}
+
+func addIntervalCPUUsageToProcesses() {
+	migrationName := "add_interval_cpu_usage_to_processes"
+	if !migrationApplied(migrationName) {
+		alterSQL := []string{
+			`ALTER TABLE processes ADD COLUMN interval_cpu_usage REAL NOT NULL DEFAULT 0;`,
+			// Existing samples only have the lifetime usage, it is the best approximation of their interval usage
+			`UPDATE processes SET interval_cpu_usage = cpu_usage WHERE cpu_usage IS NOT NULL;`,
+		}
+
+		for _, sql := range alterSQL {
+			_, err := DB.Exec(sql)
+			if err != nil {
+				fmt.Fprintf(config.SysConfig.ErrOut, "Failed to add interval cpu usage column to processes table: %s\n", err)
+				os.Exit(1)
+			}
+		}
+		recordMigration(migrationName)
+	}
+}
+
+func addResourceMetricsToProcesses() {
+	migrationName := "add_resource_metrics_to_processes"
+	if !migrationApplied(migrationName) {
+		alterSQL := []string{
+			`ALTER TABLE processes ADD COLUMN rss_bytes INTEGER NOT NULL DEFAULT 0;`,
+			`ALTER TABLE processes ADD COLUMN vms_bytes INTEGER NOT NULL DEFAULT 0;`,
+			`ALTER TABLE processes ADD COLUMN threads INTEGER NOT NULL DEFAULT 0;`,
+			`ALTER TABLE processes ADD COLUMN open_fds INTEGER NOT NULL DEFAULT 0;`,
+			`ALTER TABLE processes ADD COLUMN read_bytes INTEGER NOT NULL DEFAULT 0;`,
+			`ALTER TABLE processes ADD COLUMN write_bytes INTEGER NOT NULL DEFAULT 0;`,
+			`ALTER TABLE processes ADD COLUMN voluntary_ctx_switches INTEGER NOT NULL DEFAULT 0;`,
+			`ALTER TABLE processes ADD COLUMN involuntary_ctx_switches INTEGER NOT NULL DEFAULT 0;`,
+		}
+
+		for _, sql := range alterSQL {
+			_, err := DB.Exec(sql)
+			if err != nil {
+				fmt.Fprintf(config.SysConfig.ErrOut, "Failed to add resource metric columns to processes table: %s\n", err)
+				os.Exit(1)
+			}
+		}
+		recordMigration(migrationName)
+	}
+}
+
+func addCmdlineToProcesses() {
+	migrationName := "add_cmdline_to_processes"
+	if !migrationApplied(migrationName) {
+		alterSQL := []string{
+			`ALTER TABLE processes ADD COLUMN cmdline TEXT NOT NULL DEFAULT '';`,
+			`ALTER TABLE processes ADD COLUMN exe TEXT NOT NULL DEFAULT '';`,
+			`ALTER TABLE processes ADD COLUMN cwd TEXT NOT NULL DEFAULT '';`,
+			`ALTER TABLE processes ADD COLUMN username TEXT NOT NULL DEFAULT '';`,
+		}
+
+		for _, sql := range alterSQL {
+			_, err := DB.Exec(sql)
+			if err != nil {
+				fmt.Fprintf(config.SysConfig.ErrOut, "Failed to add command line columns to processes table: %s\n", err)
+				os.Exit(1)
+			}
+		}
+		recordMigration(migrationName)
+	}
+}
+
+func addCgroupToProcesses() {
+	migrationName := "add_cgroup_to_processes"
+	if !migrationApplied(migrationName) {
+		alterSQL := []string{
+			`ALTER TABLE processes ADD COLUMN cgroup TEXT NOT NULL DEFAULT '';`,
+			`ALTER TABLE processes ADD COLUMN container_id TEXT NOT NULL DEFAULT '';`,
+			`ALTER TABLE processes ADD COLUMN systemd_unit TEXT NOT NULL DEFAULT '';`,
+			`ALTER TABLE processes ADD COLUMN pod_uid TEXT NOT NULL DEFAULT '';`,
+			`CREATE INDEX IF NOT EXISTS idx_processes_container_id ON processes (container_id, stored_time);`,
+		}
+
+		for _, sql := range alterSQL {
+			_, err := DB.Exec(sql)
+			if err != nil {
+				fmt.Fprintf(config.SysConfig.ErrOut, "Failed to add cgroup columns to processes table: %s\n", err)
+				os.Exit(1)
+			}
+		}
+		recordMigration(migrationName)
+	}
+}
+
+func createSystemMetricsTable() {
+	migrationName := "create_system_metrics_table"
+	if !migrationApplied(migrationName) {
+		createSystemMetricsTableSQL := `
+		CREATE TABLE IF NOT EXISTS system_metrics (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			stored_time INTEGER NOT NULL,
+			cpu_usage REAL NOT NULL DEFAULT 0,
+			load1 REAL NOT NULL DEFAULT 0,
+			load5 REAL NOT NULL DEFAULT 0,
+			load15 REAL NOT NULL DEFAULT 0,
+			memory_total INTEGER NOT NULL DEFAULT 0,
+			memory_used INTEGER NOT NULL DEFAULT 0,
+			swap_total INTEGER NOT NULL DEFAULT 0,
+			swap_used INTEGER NOT NULL DEFAULT 0,
+			disk_total INTEGER NOT NULL DEFAULT 0,
+			disk_used INTEGER NOT NULL DEFAULT 0,
+			network_receive_rate REAL NOT NULL DEFAULT 0,
+			network_transmit_rate REAL NOT NULL DEFAULT 0
+		);
+		CREATE INDEX IF NOT EXISTS idx_system_metrics_stored_time ON system_metrics (stored_time);`
+
+		_, err := DB.Exec(createSystemMetricsTableSQL)
+		if err != nil {
+			fmt.Fprintf(config.SysConfig.ErrOut, "Failed to create system metrics table: %s\n", err)
+			os.Exit(1)
+		}
+		recordMigration(migrationName)
+	}
+}
+
+func createProcessRunsTable() {
+	migrationName := "create_process_runs_table"
+	if !migrationApplied(migrationName) {
+		createProcessRunsTableSQL := `
+		CREATE TABLE IF NOT EXISTS process_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			pid INTEGER NOT NULL,
+			ppid INTEGER NOT NULL DEFAULT 0,
+			created_time INTEGER NOT NULL,
+			name TEXT NOT NULL DEFAULT '',
+			cmdline TEXT NOT NULL DEFAULT '',
+			username TEXT NOT NULL DEFAULT '',
+			container_id TEXT NOT NULL DEFAULT '',
+			command_id TEXT NOT NULL DEFAULT '',
+			first_seen INTEGER NOT NULL,
+			last_seen INTEGER NOT NULL,
+			exited_time INTEGER NOT NULL DEFAULT 0,
+			samples INTEGER NOT NULL DEFAULT 0,
+			peak_cpu_usage REAL NOT NULL DEFAULT 0,
+			peak_memory_usage REAL NOT NULL DEFAULT 0,
+			peak_rss_bytes INTEGER NOT NULL DEFAULT 0,
+			UNIQUE (pid, created_time)
+		);
+		CREATE INDEX IF NOT EXISTS idx_process_runs_last_seen ON process_runs (last_seen);
+		CREATE INDEX IF NOT EXISTS idx_process_runs_command_id ON process_runs (command_id);
+		CREATE INDEX IF NOT EXISTS idx_process_runs_exited_time ON process_runs (exited_time);`
+
+		_, err := DB.Exec(createProcessRunsTableSQL)
+		if err != nil {
+			fmt.Fprintf(config.SysConfig.ErrOut, "Failed to create process runs table: %s\n", err)
+			os.Exit(1)
+		}
+		recordMigration(migrationName)
+	}
+}
+
+func normalizeProcesses() {
+	migrationName := "normalize_processes"
+	if !migrationApplied(migrationName) {
+		// The existing samples are converted and the migration is recorded in a single transaction,
+		// so an interrupted migration is run again from scratch
+		tx, err := DB.Beginx()
+		if err != nil {
+			fmt.Fprintf(config.SysConfig.ErrOut, "Failed to normalize processes table: %s\n", err)
+			os.Exit(1)
+		}
+
+		if err := convertProcesses(tx); err != nil {
+			tx.Rollback()
+			fmt.Fprintf(config.SysConfig.ErrOut, "Failed to normalize processes table: %s\n", err)
+			os.Exit(1)
+		}
+
+		if _, err := tx.Exec("INSERT INTO schema_migrations (migration_name) VALUES (?)", migrationName); err != nil {
+			tx.Rollback()
+			fmt.Fprintf(config.SysConfig.ErrOut, "Failed to record migration: %s\n", err)
+			os.Exit(1)
+		}
+
+		if err := tx.Commit(); err != nil {
+			fmt.Fprintf(config.SysConfig.ErrOut, "Failed to normalize processes table: %s\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// convertProcesses moves the processes table to an identity per process and a narrow table of samples referencing it,
+// the strings that are the same for every sample of a process are stored once and the status only as the latest one
+func convertProcesses(tx *sqlx.Tx) error {
+	createSQL := []string{
+		`CREATE TABLE IF NOT EXISTS process_identities (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			pid INTEGER NOT NULL,
+			created_time INTEGER NOT NULL DEFAULT 0,
+			name TEXT NOT NULL DEFAULT '',
+			cmdline_hash TEXT NOT NULL DEFAULT '',
+			cmdline TEXT NOT NULL DEFAULT '',
+			ppid INTEGER NOT NULL DEFAULT 0,
+			exe TEXT NOT NULL DEFAULT '',
+			cwd TEXT NOT NULL DEFAULT '',
+			username TEXT NOT NULL DEFAULT '',
+			os TEXT NOT NULL DEFAULT '',
+			platform TEXT NOT NULL DEFAULT '',
+			platform_family TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL DEFAULT '',
+			cgroup TEXT NOT NULL DEFAULT '',
+			container_id TEXT NOT NULL DEFAULT '',
+			systemd_unit TEXT NOT NULL DEFAULT '',
+			pod_uid TEXT NOT NULL DEFAULT ''
+		);`,
+		`CREATE TABLE IF NOT EXISTS process_samples (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			identity_id INTEGER NOT NULL REFERENCES process_identities (id),
+			stored_time INTEGER NOT NULL,
+			cpu_usage REAL NOT NULL DEFAULT 0,
+			interval_cpu_usage REAL NOT NULL DEFAULT 0,
+			memory_usage REAL NOT NULL DEFAULT 0,
+			rss_bytes INTEGER NOT NULL DEFAULT 0,
+			vms_bytes INTEGER NOT NULL DEFAULT 0,
+			threads INTEGER NOT NULL DEFAULT 0,
+			open_fds INTEGER NOT NULL DEFAULT 0,
+			read_bytes INTEGER NOT NULL DEFAULT 0,
+			write_bytes INTEGER NOT NULL DEFAULT 0,
+			voluntary_ctx_switches INTEGER NOT NULL DEFAULT 0,
+			involuntary_ctx_switches INTEGER NOT NULL DEFAULT 0,
+			command_id TEXT NOT NULL DEFAULT ''
+		);`,
+		// Samples of the same process share the identity, so the latest values of the rarely changing columns are kept
+		`INSERT INTO process_identities (pid, created_time, name, cmdline, ppid, exe, cwd, username, os, platform, platform_family, cgroup, container_id, systemd_unit, pod_uid)
+		SELECT pid, COALESCE(created_time, 0), name, cmdline, COALESCE(MAX(ppid), 0), MAX(exe), MAX(cwd), MAX(username),
+		       COALESCE(MAX(os), ''), COALESCE(MAX(platform), ''), COALESCE(MAX(platform_family), ''), MAX(cgroup), MAX(container_id), MAX(systemd_unit), MAX(pod_uid)
+		FROM processes
+		GROUP BY pid, COALESCE(created_time, 0), name, cmdline;`,
+		`UPDATE process_identities SET status = COALESCE((
+			SELECT p.status FROM processes p
+			WHERE p.pid = process_identities.pid AND COALESCE(p.created_time, 0) = process_identities.created_time
+			  AND p.name = process_identities.name AND p.cmdline = process_identities.cmdline
+			ORDER BY p.stored_time DESC
+			LIMIT 1
+		), '');`,
+	}
+
+	for _, sql := range createSQL {
+		if _, err := tx.Exec(sql); err != nil {
+			return err
+		}
+	}
+
+	// SQLite has no hash functions, so the hashes of the command lines are computed here
+	var cmdlines []string
+	if err := tx.Select(&cmdlines, "SELECT DISTINCT cmdline FROM process_identities"); err != nil {
+		return err
+	}
+	for _, cmdline := range cmdlines {
+		if _, err := tx.Exec("UPDATE process_identities SET cmdline_hash = ? WHERE cmdline = ?", util.HashCmdline(cmdline), cmdline); err != nil {
+			return err
+		}
+	}
+
+	convertSQL := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_process_identities_key ON process_identities (pid, created_time, name, cmdline_hash);`,
+		`CREATE INDEX IF NOT EXISTS idx_process_identities_container_id ON process_identities (container_id);`,
+		`INSERT INTO process_samples (identity_id, stored_time, cpu_usage, interval_cpu_usage, memory_usage, rss_bytes, vms_bytes, threads, open_fds,
+		                              read_bytes, write_bytes, voluntary_ctx_switches, involuntary_ctx_switches, command_id)
+		SELECT i.id, COALESCE(p.stored_time, 0), COALESCE(p.cpu_usage, 0), p.interval_cpu_usage, COALESCE(p.memory_usage, 0), p.rss_bytes, p.vms_bytes, p.threads, p.open_fds,
+		       p.read_bytes, p.write_bytes, p.voluntary_ctx_switches, p.involuntary_ctx_switches, p.command_id
+		FROM processes p
+		JOIN process_identities i ON i.pid = p.pid AND i.created_time = COALESCE(p.created_time, 0) AND i.name = p.name AND i.cmdline = p.cmdline
+		ORDER BY p.id;`,
+		`CREATE INDEX IF NOT EXISTS idx_process_samples_stored_time ON process_samples (stored_time);`,
+		`CREATE INDEX IF NOT EXISTS idx_process_samples_identity_id ON process_samples (identity_id, stored_time);`,
+		`CREATE INDEX IF NOT EXISTS idx_process_samples_command_id ON process_samples (command_id);`,
+		`DROP TABLE processes;`,
+	}
+
+	for _, sql := range convertSQL {
+		if _, err := tx.Exec(sql); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func migrationApplied(migrationName string) bool {
 	var count int
 	err := DB.Get(&count, "SELECT COUNT(*) FROM schema_migrations WHERE migration_name = ?", migrationName)
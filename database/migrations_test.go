@@ -0,0 +1,77 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/devzero-inc/oda/config"
+	"github.com/devzero-inc/oda/util"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeProcesses(t *testing.T) {
+	config.SetupSysConfig()
+
+	db, err := sqlx.Connect("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to connect to the database: %s", err)
+	}
+	// Every connection to an in-memory database gets its own database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	DB = db
+
+	// Apply the migrations up to the normalization, so the samples are stored like before it
+	ensureMigrationTableExists()
+	createProcessesTable()
+	createCommandsTable()
+	addIndexOnProcesses()
+	addCommandIdToProcesses()
+	addIntervalCPUUsageToProcesses()
+	addResourceMetricsToProcesses()
+	addCmdlineToProcesses()
+	addCgroupToProcesses()
+
+	_, err = DB.Exec(`INSERT INTO processes (pid, ppid, name, status, created_time, stored_time, os, platform, platform_family, cpu_usage, interval_cpu_usage, memory_usage, rss_bytes, command_id, cmdline, container_id)
+	VALUES (10, 1, 'make', 'S', 100, 1000, 'linux', 'linux', 'debian', 1, 10, 2, 4096, 'cmd', 'make build', ''),
+	       (10, 1, 'make', 'R', 100, 2000, 'linux', 'linux', 'debian', 1, 20, 3, 8192, 'cmd', 'make build', ''),
+	       (10, 10, 'go', 'R', 1500, 2000, 'linux', 'linux', 'debian', 5, 50, 1, 1024, 'cmd', 'go build', 'abc'),
+	       (20, NULL, 'bash', NULL, NULL, 1000, NULL, NULL, NULL, NULL, 0, NULL, 0, '', '', '')`)
+	assert.NoError(t, err)
+
+	normalizeProcesses()
+
+	var identities []struct {
+		PID         int64  `db:"pid"`
+		Name        string `db:"name"`
+		CmdlineHash string `db:"cmdline_hash"`
+		ContainerID string `db:"container_id"`
+		Status      string `db:"status"`
+		Samples     int    `db:"samples"`
+	}
+	assert.NoError(t, DB.Select(&identities, `SELECT i.pid, i.name, i.cmdline_hash, i.container_id, i.status, COUNT(s.id) AS samples
+	FROM process_identities i JOIN process_samples s ON s.identity_id = i.id
+	GROUP BY i.id ORDER BY i.pid, i.created_time`))
+	if assert.Len(t, identities, 3) {
+		assert.Equal(t, "make", identities[0].Name)
+		assert.Equal(t, util.HashCmdline("make build"), identities[0].CmdlineHash)
+		assert.Equal(t, 2, identities[0].Samples, "Samples of the same process should share the identity")
+		assert.Equal(t, "R", identities[0].Status, "Identity should keep the status of the latest sample")
+		assert.Equal(t, "go", identities[1].Name, "Reused PID should get its own identity")
+		assert.Equal(t, "abc", identities[1].ContainerID)
+		assert.Equal(t, "bash", identities[2].Name)
+	}
+
+	var peakRSS int64
+	assert.NoError(t, DB.Get(&peakRSS, "SELECT MAX(rss_bytes) FROM process_samples WHERE command_id = 'cmd'"))
+	assert.Equal(t, int64(8192), peakRSS)
+
+	var tables int
+	assert.NoError(t, DB.Get(&tables, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'processes'"))
+	assert.Zero(t, tables, "Converted processes table should be dropped")
+	assert.True(t, migrationApplied("normalize_processes"))
+
+	// The migration is recorded with the conversion, so running the migrations again does not touch the dropped table
+	normalizeProcesses()
+}
@@ -0,0 +1,144 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// List contains regular expressions selecting values, a value is accepted when it matches
+// any of the include expressions (or there are none) and none of the exclude expressions
+type List struct {
+	Include []string `mapstructure:"include"`
+	Exclude []string `mapstructure:"exclude"`
+}
+
+// Config contains the include and exclude lists for everything that is collected
+type Config struct {
+	// Commands are matched against the command line
+	Commands List `mapstructure:"commands"`
+	// Directories are matched against the directory where the command is executed
+	Directories List `mapstructure:"directories"`
+	// Repositories are matched against the repository identifier, e.g. github.com/org/repo
+	Repositories List `mapstructure:"repositories"`
+	// Processes are matched against the process name
+	Processes List `mapstructure:"processes"`
+}
+
+// matcher is a compiled List
+type matcher struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// Engine decides what is collected, all expressions are compiled once when the engine is created.
+// A nil engine accepts everything.
+type Engine struct {
+	commands     matcher
+	directories  matcher
+	repositories matcher
+	processes    matcher
+}
+
+// New compiles the configuration into an engine, reporting all invalid expressions
+func New(config Config) (*Engine, error) {
+	var errs []error
+	engine := &Engine{
+		commands:     compileList("commands", config.Commands, &errs),
+		directories:  compileList("directories", config.Directories, &errs),
+		repositories: compileList("repositories", config.Repositories, &errs),
+		processes:    compileList("processes", config.Processes, &errs),
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid filters: %w", errors.Join(errs...))
+	}
+
+	return engine, nil
+}
+
+// compileList compiles the list, collecting the errors of invalid expressions
+func compileList(name string, list List, errs *[]error) matcher {
+	return matcher{
+		include: compileExpressions(name+".include", list.Include, errs),
+		exclude: compileExpressions(name+".exclude", list.Exclude, errs),
+	}
+}
+
+// compileExpressions compiles the expressions, collecting the errors of invalid ones
+func compileExpressions(name string, expressions []string, errs *[]error) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(expressions))
+	for _, expr := range expressions {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %q: %w", name, expr, err))
+			continue
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled
+}
+
+// excludes reports whether the value matches any exclude expression
+func (m *matcher) excludes(value string) bool {
+	for _, re := range m.exclude {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// accepts reports whether the value matches any include expression, or there are none, and no exclude expression
+func (m *matcher) accepts(value string) bool {
+	if m.excludes(value) {
+		return false
+	}
+
+	if len(m.include) == 0 {
+		return true
+	}
+
+	for _, re := range m.include {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowCommand reports whether the command executed in the directory should be collected
+func (e *Engine) AllowCommand(command, directory string) bool {
+	if e == nil {
+		return true
+	}
+	return e.commands.accepts(command) && e.directories.accepts(directory)
+}
+
+// AllowRepository reports whether commands executed in the repository should be collected,
+// commands executed outside of a repository are matched against an empty identifier
+func (e *Engine) AllowRepository(repository string) bool {
+	if e == nil {
+		return true
+	}
+	return e.repositories.accepts(repository)
+}
+
+// AllowProcess reports whether the process with the name should be collected
+func (e *Engine) AllowProcess(name string) bool {
+	if e == nil {
+		return true
+	}
+	return e.processes.accepts(name)
+}
+
+// AllowProcessDetails reports whether the command line and working directory of a process should be collected.
+// Only the exclude expressions of the commands and directories are applied, the include expressions select
+// the shell commands to collect and would hide the details of every other process.
+func (e *Engine) AllowProcessDetails(cmdline, cwd string) (allowCmdline, allowCwd bool) {
+	if e == nil {
+		return true, true
+	}
+	return !e.commands.excludes(cmdline), !e.directories.excludes(cwd)
+}
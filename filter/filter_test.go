@@ -0,0 +1,58 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngine(t *testing.T) {
+	engine, err := New(Config{
+		Commands:     List{Exclude: []string{"^vim", "^ssh"}},
+		Directories:  List{Include: []string{"^/work"}, Exclude: []string{"^/work/private"}},
+		Repositories: List{Include: []string{"^github.com/org/", "^$"}},
+		Processes:    List{Exclude: []string{"^kworker"}},
+	})
+	assert.NoError(t, err)
+
+	assert.True(t, engine.AllowCommand("make build", "/work/api"))
+	assert.False(t, engine.AllowCommand("vim main.go", "/work/api"))
+	assert.False(t, engine.AllowCommand("make build", "/tmp"))
+	assert.False(t, engine.AllowCommand("make build", "/work/private/notes"))
+
+	assert.True(t, engine.AllowRepository("github.com/org/api"))
+	assert.True(t, engine.AllowRepository(""))
+	assert.False(t, engine.AllowRepository("github.com/other/api"))
+
+	assert.True(t, engine.AllowProcess("go"))
+	assert.False(t, engine.AllowProcess("kworker/0:1"))
+
+	allowCmdline, allowCwd := engine.AllowProcessDetails("node server.js", "/home/dev")
+	assert.True(t, allowCmdline, "Include expressions should not hide process details")
+	assert.True(t, allowCwd, "Include expressions should not hide process details")
+	allowCmdline, allowCwd = engine.AllowProcessDetails("ssh host", "/work/private/notes")
+	assert.False(t, allowCmdline)
+	assert.False(t, allowCwd)
+}
+
+func TestNilEngineAcceptsEverything(t *testing.T) {
+	var engine *Engine
+
+	assert.True(t, engine.AllowCommand("vim", "/"))
+	assert.True(t, engine.AllowRepository("github.com/org/api"))
+	assert.True(t, engine.AllowProcess("kworker"))
+	allowCmdline, allowCwd := engine.AllowProcessDetails("vim", "/")
+	assert.True(t, allowCmdline)
+	assert.True(t, allowCwd)
+}
+
+func TestNewReportsAllInvalidExpressions(t *testing.T) {
+	_, err := New(Config{
+		Commands:  List{Include: []string{"("}},
+		Processes: List{Exclude: []string{"[", "ok"}},
+	})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "commands.include")
+	assert.Contains(t, err.Error(), "processes.exclude")
+}
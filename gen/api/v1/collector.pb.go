@@ -100,18 +100,31 @@ type Command struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id            int64  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                                            // Unique identifier for the command.
-	Category      string `protobuf:"bytes,2,opt,name=category,proto3" json:"category,omitempty"`                                 // Category of the command (e.g., system, user).
-	Command       string `protobuf:"bytes,3,opt,name=command,proto3" json:"command,omitempty"`                                   // The actual command string.
-	User          string `protobuf:"bytes,4,opt,name=user,proto3" json:"user,omitempty"`                                         // The user who executed the command.
-	Directory     string `protobuf:"bytes,5,opt,name=directory,proto3" json:"directory,omitempty"`                               // The directory from which the command was executed.
-	ExecutionTime int64  `protobuf:"varint,6,opt,name=execution_time,json=executionTime,proto3" json:"execution_time,omitempty"` // Execution time of the command in milliseconds.
-	StartTime     int64  `protobuf:"varint,7,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`             // Start time of the command execution (Unix timestamp).
-	EndTime       int64  `protobuf:"varint,8,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`                   // End time of the command execution (Unix timestamp).
-	Result        string `protobuf:"bytes,9,opt,name=result,proto3" json:"result,omitempty"`                                     // Result of executed command => success/failure
-	Status        string `protobuf:"bytes,10,opt,name=status,proto3" json:"status,omitempty"`                                    // Status of executed command
-	Repository    string `protobuf:"bytes,11,opt,name=repository,proto3" json:"repository,omitempty"`                            // Repository is repository where commands are executed
-	Pid           int64  `protobuf:"varint,12,opt,name=pid,proto3" json:"pid,omitempty"`                                         // PID of the command
+	Id              int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                                                      // Unique identifier for the command.
+	Category        string   `protobuf:"bytes,2,opt,name=category,proto3" json:"category,omitempty"`                                           // Category of the command (e.g., system, user).
+	Command         string   `protobuf:"bytes,3,opt,name=command,proto3" json:"command,omitempty"`                                             // The actual command string.
+	User            string   `protobuf:"bytes,4,opt,name=user,proto3" json:"user,omitempty"`                                                   // The user who executed the command.
+	Directory       string   `protobuf:"bytes,5,opt,name=directory,proto3" json:"directory,omitempty"`                                         // The directory from which the command was executed.
+	ExecutionTime   int64    `protobuf:"varint,6,opt,name=execution_time,json=executionTime,proto3" json:"execution_time,omitempty"`           // Execution time of the command in milliseconds.
+	StartTime       int64    `protobuf:"varint,7,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`                       // Start time of the command execution (Unix timestamp).
+	EndTime         int64    `protobuf:"varint,8,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`                             // End time of the command execution (Unix timestamp).
+	Result          string   `protobuf:"bytes,9,opt,name=result,proto3" json:"result,omitempty"`                                               // Result of executed command => success/failure
+	Status          string   `protobuf:"bytes,10,opt,name=status,proto3" json:"status,omitempty"`                                              // Status of executed command
+	Repository      string   `protobuf:"bytes,11,opt,name=repository,proto3" json:"repository,omitempty"`                                      // Repository is repository where commands are executed
+	Pid             int64    `protobuf:"varint,12,opt,name=pid,proto3" json:"pid,omitempty"`                                                   // PID of the command
+	GitBranch       string   `protobuf:"bytes,13,opt,name=git_branch,json=gitBranch,proto3" json:"git_branch,omitempty"`                       // Branch checked out when the command started
+	GitCommit       string   `protobuf:"bytes,14,opt,name=git_commit,json=gitCommit,proto3" json:"git_commit,omitempty"`                       // SHA of HEAD when the command started
+	GitDirty        *bool    `protobuf:"varint,15,opt,name=git_dirty,json=gitDirty,proto3,oneof" json:"git_dirty,omitempty"`                   // Whether the working tree had uncommitted changes, unset if unknown
+	RepositoryId    string   `protobuf:"bytes,16,opt,name=repository_id,json=repositoryId,proto3" json:"repository_id,omitempty"`              // Normalized repository identifier in the form of host/org/repo
+	RepositoryPath  string   `protobuf:"bytes,17,opt,name=repository_path,json=repositoryPath,proto3" json:"repository_path,omitempty"`        // Directory relative to the repository root
+	Uuid            string   `protobuf:"bytes,18,opt,name=uuid,proto3" json:"uuid,omitempty"`                                                  // Unique identifier of the command assigned by the shell hooks
+	PeakCpuUsage    float64  `protobuf:"fixed64,19,opt,name=peak_cpu_usage,json=peakCpuUsage,proto3" json:"peak_cpu_usage,omitempty"`          // Highest CPU usage percentage of the command's process tree in a single sample.
+	AvgCpuUsage     float64  `protobuf:"fixed64,20,opt,name=avg_cpu_usage,json=avgCpuUsage,proto3" json:"avg_cpu_usage,omitempty"`             // Average CPU usage percentage of the command's process tree.
+	CpuSeconds      float64  `protobuf:"fixed64,21,opt,name=cpu_seconds,json=cpuSeconds,proto3" json:"cpu_seconds,omitempty"`                  // CPU time in seconds consumed by the command's process tree.
+	PeakMemoryUsage float64  `protobuf:"fixed64,22,opt,name=peak_memory_usage,json=peakMemoryUsage,proto3" json:"peak_memory_usage,omitempty"` // Highest memory usage percentage of the command's process tree in a single sample.
+	Subcategory     string   `protobuf:"bytes,23,opt,name=subcategory,proto3" json:"subcategory,omitempty"`                                    // Subcommand of the executed tool (e.g., commit for git commit).
+	Tags            []string `protobuf:"bytes,24,rep,name=tags,proto3" json:"tags,omitempty"`                                                  // Tags added by the user defined rules.
+	SessionId       string   `protobuf:"bytes,25,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`                       // Identifier of the shell session in which the command was executed.
 }
 
 func (x *Command) Reset() {
@@ -230,24 +243,133 @@ func (x *Command) GetPid() int64 {
 	return 0
 }
 
+func (x *Command) GetGitBranch() string {
+	if x != nil {
+		return x.GitBranch
+	}
+	return ""
+}
+
+func (x *Command) GetGitCommit() string {
+	if x != nil {
+		return x.GitCommit
+	}
+	return ""
+}
+
+func (x *Command) GetGitDirty() bool {
+	if x != nil && x.GitDirty != nil {
+		return *x.GitDirty
+	}
+	return false
+}
+
+func (x *Command) GetRepositoryId() string {
+	if x != nil {
+		return x.RepositoryId
+	}
+	return ""
+}
+
+func (x *Command) GetRepositoryPath() string {
+	if x != nil {
+		return x.RepositoryPath
+	}
+	return ""
+}
+
+func (x *Command) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *Command) GetPeakCpuUsage() float64 {
+	if x != nil {
+		return x.PeakCpuUsage
+	}
+	return 0
+}
+
+func (x *Command) GetAvgCpuUsage() float64 {
+	if x != nil {
+		return x.AvgCpuUsage
+	}
+	return 0
+}
+
+func (x *Command) GetCpuSeconds() float64 {
+	if x != nil {
+		return x.CpuSeconds
+	}
+	return 0
+}
+
+func (x *Command) GetPeakMemoryUsage() float64 {
+	if x != nil {
+		return x.PeakMemoryUsage
+	}
+	return 0
+}
+
+func (x *Command) GetSubcategory() string {
+	if x != nil {
+		return x.Subcategory
+	}
+	return ""
+}
+
+func (x *Command) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Command) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
 // Define a message representing a process, including its metadata and resource usage.
 type Process struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id             int64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                                              // Unique identifier for the process.
-	Pid            int64   `protobuf:"varint,2,opt,name=pid,proto3" json:"pid,omitempty"`                                            // Process ID.
-	Name           string  `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`                                           // Process name.
-	Status         string  `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`                                       // Current status of the process (e.g., running, sleeping).
-	CreatedTime    int64   `protobuf:"varint,5,opt,name=created_time,json=createdTime,proto3" json:"created_time,omitempty"`         // Creation time of the process (Unix timestamp).
-	StoredTime     int64   `protobuf:"varint,6,opt,name=stored_time,json=storedTime,proto3" json:"stored_time,omitempty"`            // Time at which the process information was stored (Unix timestamp).
-	Os             string  `protobuf:"bytes,7,opt,name=os,proto3" json:"os,omitempty"`                                               // Operating system the process is running on.
-	Platform       string  `protobuf:"bytes,8,opt,name=platform,proto3" json:"platform,omitempty"`                                   // Platform information (e.g., Linux, Windows).
-	PlatformFamily string  `protobuf:"bytes,9,opt,name=platform_family,json=platformFamily,proto3" json:"platform_family,omitempty"` // More detailed platform family information.
-	CpuUsage       float64 `protobuf:"fixed64,10,opt,name=cpu_usage,json=cpuUsage,proto3" json:"cpu_usage,omitempty"`                // CPU usage percentage by the process.
-	MemoryUsage    float64 `protobuf:"fixed64,11,opt,name=memory_usage,json=memoryUsage,proto3" json:"memory_usage,omitempty"`       // Memory usage by the process in megabytes.
-	Ppid           int64   `protobuf:"varint,12,opt,name=ppid,proto3" json:"ppid,omitempty"`                                         // Parent process ID.
+	Id                     int64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                                                                          // Unique identifier for the process.
+	Pid                    int64   `protobuf:"varint,2,opt,name=pid,proto3" json:"pid,omitempty"`                                                                        // Process ID.
+	Name                   string  `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`                                                                       // Process name.
+	Status                 string  `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`                                                                   // Current status of the process (e.g., running, sleeping).
+	CreatedTime            int64   `protobuf:"varint,5,opt,name=created_time,json=createdTime,proto3" json:"created_time,omitempty"`                                     // Creation time of the process (Unix timestamp).
+	StoredTime             int64   `protobuf:"varint,6,opt,name=stored_time,json=storedTime,proto3" json:"stored_time,omitempty"`                                        // Time at which the process information was stored (Unix timestamp).
+	Os                     string  `protobuf:"bytes,7,opt,name=os,proto3" json:"os,omitempty"`                                                                           // Operating system the process is running on.
+	Platform               string  `protobuf:"bytes,8,opt,name=platform,proto3" json:"platform,omitempty"`                                                               // Platform information (e.g., Linux, Windows).
+	PlatformFamily         string  `protobuf:"bytes,9,opt,name=platform_family,json=platformFamily,proto3" json:"platform_family,omitempty"`                             // More detailed platform family information.
+	CpuUsage               float64 `protobuf:"fixed64,10,opt,name=cpu_usage,json=cpuUsage,proto3" json:"cpu_usage,omitempty"`                                            // CPU usage percentage by the process.
+	MemoryUsage            float64 `protobuf:"fixed64,11,opt,name=memory_usage,json=memoryUsage,proto3" json:"memory_usage,omitempty"`                                   // Memory usage by the process in megabytes.
+	Ppid                   int64   `protobuf:"varint,12,opt,name=ppid,proto3" json:"ppid,omitempty"`                                                                     // Parent process ID.
+	CommandId              string  `protobuf:"bytes,13,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`                                           // UUID of the command that spawned the process, empty if not attributed.
+	IntervalCpuUsage       float64 `protobuf:"fixed64,14,opt,name=interval_cpu_usage,json=intervalCpuUsage,proto3" json:"interval_cpu_usage,omitempty"`                  // CPU usage percentage since the previous sample, cpu_usage is averaged over the process lifetime.
+	RssBytes               int64   `protobuf:"varint,15,opt,name=rss_bytes,json=rssBytes,proto3" json:"rss_bytes,omitempty"`                                             // Resident memory size in bytes.
+	VmsBytes               int64   `protobuf:"varint,16,opt,name=vms_bytes,json=vmsBytes,proto3" json:"vms_bytes,omitempty"`                                             // Virtual memory size in bytes.
+	Threads                int64   `protobuf:"varint,17,opt,name=threads,proto3" json:"threads,omitempty"`                                                               // Number of threads.
+	OpenFds                int64   `protobuf:"varint,18,opt,name=open_fds,json=openFds,proto3" json:"open_fds,omitempty"`                                                // Number of open file descriptors, 0 if unavailable.
+	ReadBytes              int64   `protobuf:"varint,19,opt,name=read_bytes,json=readBytes,proto3" json:"read_bytes,omitempty"`                                          // Bytes read from storage over the lifetime of the process.
+	WriteBytes             int64   `protobuf:"varint,20,opt,name=write_bytes,json=writeBytes,proto3" json:"write_bytes,omitempty"`                                       // Bytes written to storage over the lifetime of the process.
+	VoluntaryCtxSwitches   int64   `protobuf:"varint,21,opt,name=voluntary_ctx_switches,json=voluntaryCtxSwitches,proto3" json:"voluntary_ctx_switches,omitempty"`       // Voluntary context switches over the lifetime of the process.
+	InvoluntaryCtxSwitches int64   `protobuf:"varint,22,opt,name=involuntary_ctx_switches,json=involuntaryCtxSwitches,proto3" json:"involuntary_ctx_switches,omitempty"` // Involuntary context switches over the lifetime of the process.
+	Cmdline                string  `protobuf:"bytes,23,opt,name=cmdline,proto3" json:"cmdline,omitempty"`                                                                // Full command line of the process, with secrets redacted.
+	Exe                    string  `protobuf:"bytes,24,opt,name=exe,proto3" json:"exe,omitempty"`                                                                        // Path of the executable, empty if unavailable.
+	Cwd                    string  `protobuf:"bytes,25,opt,name=cwd,proto3" json:"cwd,omitempty"`                                                                        // Working directory, empty if unavailable.
+	Username               string  `protobuf:"bytes,26,opt,name=username,proto3" json:"username,omitempty"`                                                              // Owner of the process.
+	Cgroup                 string  `protobuf:"bytes,27,opt,name=cgroup,proto3" json:"cgroup,omitempty"`                                                                  // Control group path of the process, empty outside of Linux.
+	ContainerId            string  `protobuf:"bytes,28,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`                                     // Container ID derived from the control group, empty outside of containers.
+	SystemdUnit            string  `protobuf:"bytes,29,opt,name=systemd_unit,json=systemdUnit,proto3" json:"systemd_unit,omitempty"`                                     // Systemd unit derived from the control group.
+	PodUid                 string  `protobuf:"bytes,30,opt,name=pod_uid,json=podUid,proto3" json:"pod_uid,omitempty"`                                                    // Kubernetes pod UID derived from the control group.
 }
 
 func (x *Process) Reset() {
@@ -366,6 +488,284 @@ func (x *Process) GetPpid() int64 {
 	return 0
 }
 
+func (x *Process) GetCommandId() string {
+	if x != nil {
+		return x.CommandId
+	}
+	return ""
+}
+
+func (x *Process) GetIntervalCpuUsage() float64 {
+	if x != nil {
+		return x.IntervalCpuUsage
+	}
+	return 0
+}
+
+func (x *Process) GetRssBytes() int64 {
+	if x != nil {
+		return x.RssBytes
+	}
+	return 0
+}
+
+func (x *Process) GetVmsBytes() int64 {
+	if x != nil {
+		return x.VmsBytes
+	}
+	return 0
+}
+
+func (x *Process) GetThreads() int64 {
+	if x != nil {
+		return x.Threads
+	}
+	return 0
+}
+
+func (x *Process) GetOpenFds() int64 {
+	if x != nil {
+		return x.OpenFds
+	}
+	return 0
+}
+
+func (x *Process) GetReadBytes() int64 {
+	if x != nil {
+		return x.ReadBytes
+	}
+	return 0
+}
+
+func (x *Process) GetWriteBytes() int64 {
+	if x != nil {
+		return x.WriteBytes
+	}
+	return 0
+}
+
+func (x *Process) GetVoluntaryCtxSwitches() int64 {
+	if x != nil {
+		return x.VoluntaryCtxSwitches
+	}
+	return 0
+}
+
+func (x *Process) GetInvoluntaryCtxSwitches() int64 {
+	if x != nil {
+		return x.InvoluntaryCtxSwitches
+	}
+	return 0
+}
+
+func (x *Process) GetCmdline() string {
+	if x != nil {
+		return x.Cmdline
+	}
+	return ""
+}
+
+func (x *Process) GetExe() string {
+	if x != nil {
+		return x.Exe
+	}
+	return ""
+}
+
+func (x *Process) GetCwd() string {
+	if x != nil {
+		return x.Cwd
+	}
+	return ""
+}
+
+func (x *Process) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *Process) GetCgroup() string {
+	if x != nil {
+		return x.Cgroup
+	}
+	return ""
+}
+
+func (x *Process) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *Process) GetSystemdUnit() string {
+	if x != nil {
+		return x.SystemdUnit
+	}
+	return ""
+}
+
+func (x *Process) GetPodUid() string {
+	if x != nil {
+		return x.PodUid
+	}
+	return ""
+}
+
+// Define a message representing a sample of the host-level system metrics.
+type SystemMetrics struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id                  int64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                                                                  // Unique identifier for the sample.
+	StoredTime          int64   `protobuf:"varint,2,opt,name=stored_time,json=storedTime,proto3" json:"stored_time,omitempty"`                                // Time at which the sample was taken (Unix timestamp in milliseconds).
+	CpuUsage            float64 `protobuf:"fixed64,3,opt,name=cpu_usage,json=cpuUsage,proto3" json:"cpu_usage,omitempty"`                                     // CPU usage percentage of the host since the previous sample, across all CPUs.
+	Load1               float64 `protobuf:"fixed64,4,opt,name=load1,proto3" json:"load1,omitempty"`                                                           // Load average over 1 minute.
+	Load5               float64 `protobuf:"fixed64,5,opt,name=load5,proto3" json:"load5,omitempty"`                                                           // Load average over 5 minutes.
+	Load15              float64 `protobuf:"fixed64,6,opt,name=load15,proto3" json:"load15,omitempty"`                                                         // Load average over 15 minutes.
+	MemoryTotal         int64   `protobuf:"varint,7,opt,name=memory_total,json=memoryTotal,proto3" json:"memory_total,omitempty"`                             // Total memory in bytes.
+	MemoryUsed          int64   `protobuf:"varint,8,opt,name=memory_used,json=memoryUsed,proto3" json:"memory_used,omitempty"`                                // Used memory in bytes, excluding reclaimable caches.
+	SwapTotal           int64   `protobuf:"varint,9,opt,name=swap_total,json=swapTotal,proto3" json:"swap_total,omitempty"`                                   // Total swap in bytes.
+	SwapUsed            int64   `protobuf:"varint,10,opt,name=swap_used,json=swapUsed,proto3" json:"swap_used,omitempty"`                                     // Used swap in bytes.
+	DiskTotal           int64   `protobuf:"varint,11,opt,name=disk_total,json=diskTotal,proto3" json:"disk_total,omitempty"`                                  // Total size of the sampled filesystem in bytes.
+	DiskUsed            int64   `protobuf:"varint,12,opt,name=disk_used,json=diskUsed,proto3" json:"disk_used,omitempty"`                                     // Used space of the sampled filesystem in bytes.
+	NetworkReceiveRate  float64 `protobuf:"fixed64,13,opt,name=network_receive_rate,json=networkReceiveRate,proto3" json:"network_receive_rate,omitempty"`    // Bytes per second received on all interfaces except loopback.
+	NetworkTransmitRate float64 `protobuf:"fixed64,14,opt,name=network_transmit_rate,json=networkTransmitRate,proto3" json:"network_transmit_rate,omitempty"` // Bytes per second transmitted on all interfaces except loopback.
+}
+
+func (x *SystemMetrics) Reset() {
+	*x = SystemMetrics{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1_collector_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SystemMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SystemMetrics) ProtoMessage() {}
+
+func (x *SystemMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_collector_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SystemMetrics.ProtoReflect.Descriptor instead.
+func (*SystemMetrics) Descriptor() ([]byte, []int) {
+	return file_api_v1_collector_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SystemMetrics) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *SystemMetrics) GetStoredTime() int64 {
+	if x != nil {
+		return x.StoredTime
+	}
+	return 0
+}
+
+func (x *SystemMetrics) GetCpuUsage() float64 {
+	if x != nil {
+		return x.CpuUsage
+	}
+	return 0
+}
+
+func (x *SystemMetrics) GetLoad1() float64 {
+	if x != nil {
+		return x.Load1
+	}
+	return 0
+}
+
+func (x *SystemMetrics) GetLoad5() float64 {
+	if x != nil {
+		return x.Load5
+	}
+	return 0
+}
+
+func (x *SystemMetrics) GetLoad15() float64 {
+	if x != nil {
+		return x.Load15
+	}
+	return 0
+}
+
+func (x *SystemMetrics) GetMemoryTotal() int64 {
+	if x != nil {
+		return x.MemoryTotal
+	}
+	return 0
+}
+
+func (x *SystemMetrics) GetMemoryUsed() int64 {
+	if x != nil {
+		return x.MemoryUsed
+	}
+	return 0
+}
+
+func (x *SystemMetrics) GetSwapTotal() int64 {
+	if x != nil {
+		return x.SwapTotal
+	}
+	return 0
+}
+
+func (x *SystemMetrics) GetSwapUsed() int64 {
+	if x != nil {
+		return x.SwapUsed
+	}
+	return 0
+}
+
+func (x *SystemMetrics) GetDiskTotal() int64 {
+	if x != nil {
+		return x.DiskTotal
+	}
+	return 0
+}
+
+func (x *SystemMetrics) GetDiskUsed() int64 {
+	if x != nil {
+		return x.DiskUsed
+	}
+	return 0
+}
+
+func (x *SystemMetrics) GetNetworkReceiveRate() float64 {
+	if x != nil {
+		return x.NetworkReceiveRate
+	}
+	return 0
+}
+
+func (x *SystemMetrics) GetNetworkTransmitRate() float64 {
+	if x != nil {
+		return x.NetworkTransmitRate
+	}
+	return 0
+}
+
 // Defines a request for sending a collection of commands.
 type SendCommandsRequest struct {
 	state         protoimpl.MessageState
@@ -379,7 +779,7 @@ type SendCommandsRequest struct {
 func (x *SendCommandsRequest) Reset() {
 	*x = SendCommandsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_v1_collector_proto_msgTypes[3]
+		mi := &file_api_v1_collector_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -392,7 +792,7 @@ func (x *SendCommandsRequest) String() string {
 func (*SendCommandsRequest) ProtoMessage() {}
 
 func (x *SendCommandsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_collector_proto_msgTypes[3]
+	mi := &file_api_v1_collector_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -405,7 +805,7 @@ func (x *SendCommandsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SendCommandsRequest.ProtoReflect.Descriptor instead.
 func (*SendCommandsRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_collector_proto_rawDescGZIP(), []int{3}
+	return file_api_v1_collector_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *SendCommandsRequest) GetCommands() []*Command {
@@ -435,7 +835,7 @@ type SendProcessesRequest struct {
 func (x *SendProcessesRequest) Reset() {
 	*x = SendProcessesRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_v1_collector_proto_msgTypes[4]
+		mi := &file_api_v1_collector_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -448,7 +848,7 @@ func (x *SendProcessesRequest) String() string {
 func (*SendProcessesRequest) ProtoMessage() {}
 
 func (x *SendProcessesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_collector_proto_msgTypes[4]
+	mi := &file_api_v1_collector_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -461,7 +861,7 @@ func (x *SendProcessesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SendProcessesRequest.ProtoReflect.Descriptor instead.
 func (*SendProcessesRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_collector_proto_rawDescGZIP(), []int{4}
+	return file_api_v1_collector_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *SendProcessesRequest) GetProcesses() []*Process {
@@ -478,6 +878,62 @@ func (x *SendProcessesRequest) GetAuth() *Auth {
 	return nil
 }
 
+// Defines a request for sending a collection of system metrics samples.
+type SendSystemMetricsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Metrics []*SystemMetrics `protobuf:"bytes,1,rep,name=metrics,proto3" json:"metrics,omitempty"` // A list of system metrics samples.
+	Auth    *Auth            `protobuf:"bytes,2,opt,name=auth,proto3,oneof" json:"auth,omitempty"` // Optional auth configuration
+}
+
+func (x *SendSystemMetricsRequest) Reset() {
+	*x = SendSystemMetricsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1_collector_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SendSystemMetricsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendSystemMetricsRequest) ProtoMessage() {}
+
+func (x *SendSystemMetricsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_collector_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendSystemMetricsRequest.ProtoReflect.Descriptor instead.
+func (*SendSystemMetricsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_collector_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SendSystemMetricsRequest) GetMetrics() []*SystemMetrics {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *SendSystemMetricsRequest) GetAuth() *Auth {
+	if x != nil {
+		return x.Auth
+	}
+	return nil
+}
+
 var File_api_v1_collector_proto protoreflect.FileDescriptor
 
 var file_api_v1_collector_proto_rawDesc = []byte{
@@ -494,7 +950,7 @@ var file_api_v1_collector_proto_rawDesc = []byte{
 	0x12, 0x1d, 0x0a, 0x0a, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x04,
 	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x75, 0x73, 0x65, 0x72, 0x45, 0x6d, 0x61, 0x69, 0x6c, 0x42,
 	0x0f, 0x0a, 0x0d, 0x5f, 0x77, 0x6f, 0x72, 0x6b, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64,
-	0x22, 0xc4, 0x02, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02,
+	0x22, 0x80, 0x06, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02,
 	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08,
 	0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
 	0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d,
@@ -514,56 +970,161 @@ var file_api_v1_collector_proto_rawDesc = []byte{
 	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74,
 	0x6f, 0x72, 0x79, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x65, 0x70, 0x6f, 0x73,
 	0x69, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x69, 0x64, 0x18, 0x0c, 0x20, 0x01,
-	0x28, 0x03, 0x52, 0x03, 0x70, 0x69, 0x64, 0x22, 0xc4, 0x02, 0x0a, 0x07, 0x50, 0x72, 0x6f, 0x63,
-	0x65, 0x73, 0x73, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
-	0x02, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
-	0x52, 0x03, 0x70, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x74, 0x69, 0x6d,
-	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64,
-	0x54, 0x69, 0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x5f, 0x74,
-	0x69, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x73, 0x74, 0x6f, 0x72, 0x65,
-	0x64, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x6f, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x02, 0x6f, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72,
-	0x6d, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72,
-	0x6d, 0x12, 0x27, 0x0a, 0x0f, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x66, 0x61,
-	0x6d, 0x69, 0x6c, 0x79, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x70, 0x6c, 0x61, 0x74,
-	0x66, 0x6f, 0x72, 0x6d, 0x46, 0x61, 0x6d, 0x69, 0x6c, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x70,
-	0x75, 0x5f, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x63,
-	0x70, 0x75, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65, 0x6d, 0x6f, 0x72,
-	0x79, 0x5f, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x6d,
-	0x65, 0x6d, 0x6f, 0x72, 0x79, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x70,
-	0x69, 0x64, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x70, 0x70, 0x69, 0x64, 0x22, 0x72,
-	0x0a, 0x13, 0x53, 0x65, 0x6e, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2b, 0x0a, 0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31,
-	0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
-	0x64, 0x73, 0x12, 0x25, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x48, 0x00,
-	0x52, 0x04, 0x61, 0x75, 0x74, 0x68, 0x88, 0x01, 0x01, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x61, 0x75,
-	0x74, 0x68, 0x22, 0x75, 0x0a, 0x14, 0x53, 0x65, 0x6e, 0x64, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73,
-	0x73, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2d, 0x0a, 0x09, 0x70, 0x72,
-	0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e,
-	0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x52, 0x09,
-	0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x73, 0x12, 0x25, 0x0a, 0x04, 0x61, 0x75, 0x74,
-	0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31,
-	0x2e, 0x41, 0x75, 0x74, 0x68, 0x48, 0x00, 0x52, 0x04, 0x61, 0x75, 0x74, 0x68, 0x88, 0x01, 0x01,
-	0x42, 0x07, 0x0a, 0x05, 0x5f, 0x61, 0x75, 0x74, 0x68, 0x32, 0x9e, 0x01, 0x0a, 0x10, 0x43, 0x6f,
-	0x6c, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x43,
-	0x0a, 0x0c, 0x53, 0x65, 0x6e, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x1b,
-	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x43, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f,
-	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d,
-	0x70, 0x74, 0x79, 0x12, 0x45, 0x0a, 0x0d, 0x53, 0x65, 0x6e, 0x64, 0x50, 0x72, 0x6f, 0x63, 0x65,
-	0x73, 0x73, 0x65, 0x73, 0x12, 0x1c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65,
-	0x6e, 0x64, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x42, 0x39, 0x0a, 0x0a, 0x67, 0x65,
-	0x6e, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x50, 0x01, 0x5a, 0x29, 0x67, 0x69, 0x74, 0x68,
-	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x64, 0x65, 0x76, 0x7a, 0x65, 0x72, 0x6f, 0x2d, 0x69,
-	0x6e, 0x63, 0x2f, 0x6f, 0x64, 0x61, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76,
-	0x31, 0x3b, 0x67, 0x65, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x28, 0x03, 0x52, 0x03, 0x70, 0x69, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x67, 0x69, 0x74, 0x5f, 0x62,
+	0x72, 0x61, 0x6e, 0x63, 0x68, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x67, 0x69, 0x74,
+	0x42, 0x72, 0x61, 0x6e, 0x63, 0x68, 0x12, 0x1d, 0x0a, 0x0a, 0x67, 0x69, 0x74, 0x5f, 0x63, 0x6f,
+	0x6d, 0x6d, 0x69, 0x74, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x67, 0x69, 0x74, 0x43,
+	0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x12, 0x20, 0x0a, 0x09, 0x67, 0x69, 0x74, 0x5f, 0x64, 0x69, 0x72,
+	0x74, 0x79, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x08, 0x67, 0x69, 0x74, 0x44,
+	0x69, 0x72, 0x74, 0x79, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65, 0x70, 0x6f, 0x73,
+	0x69, 0x74, 0x6f, 0x72, 0x79, 0x5f, 0x69, 0x64, 0x18, 0x10, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
+	0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79, 0x49, 0x64, 0x12, 0x27, 0x0a, 0x0f,
+	0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18,
+	0x11, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72,
+	0x79, 0x50, 0x61, 0x74, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x75, 0x69, 0x64, 0x18, 0x12, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x75, 0x69, 0x64, 0x12, 0x24, 0x0a, 0x0e, 0x70, 0x65, 0x61,
+	0x6b, 0x5f, 0x63, 0x70, 0x75, 0x5f, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18, 0x13, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x0c, 0x70, 0x65, 0x61, 0x6b, 0x43, 0x70, 0x75, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12,
+	0x22, 0x0a, 0x0d, 0x61, 0x76, 0x67, 0x5f, 0x63, 0x70, 0x75, 0x5f, 0x75, 0x73, 0x61, 0x67, 0x65,
+	0x18, 0x14, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x61, 0x76, 0x67, 0x43, 0x70, 0x75, 0x55, 0x73,
+	0x61, 0x67, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x70, 0x75, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e,
+	0x64, 0x73, 0x18, 0x15, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x63, 0x70, 0x75, 0x53, 0x65, 0x63,
+	0x6f, 0x6e, 0x64, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x70, 0x65, 0x61, 0x6b, 0x5f, 0x6d, 0x65, 0x6d,
+	0x6f, 0x72, 0x79, 0x5f, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18, 0x16, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0f, 0x70, 0x65, 0x61, 0x6b, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x55, 0x73, 0x61, 0x67, 0x65,
+	0x12, 0x20, 0x0a, 0x0b, 0x73, 0x75, 0x62, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x18,
+	0x17, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x73, 0x75, 0x62, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f,
+	0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x18, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x5f, 0x69, 0x64, 0x18, 0x19, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x49, 0x64, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x67, 0x69, 0x74, 0x5f, 0x64, 0x69,
+	0x72, 0x74, 0x79, 0x22, 0x81, 0x07, 0x0a, 0x07, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x12,
+	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12,
+	0x10, 0x0a, 0x03, 0x70, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x70, 0x69,
+	0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x21, 0x0a,
+	0x0c, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0b, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x54, 0x69, 0x6d, 0x65,
+	0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x54, 0x69, 0x6d,
+	0x65, 0x12, 0x0e, 0x0a, 0x02, 0x6f, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x6f,
+	0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x27, 0x0a,
+	0x0f, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x66, 0x61, 0x6d, 0x69, 0x6c, 0x79,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d,
+	0x46, 0x61, 0x6d, 0x69, 0x6c, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x70, 0x75, 0x5f, 0x75, 0x73,
+	0x61, 0x67, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x63, 0x70, 0x75, 0x55, 0x73,
+	0x61, 0x67, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x75, 0x73,
+	0x61, 0x67, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x6d, 0x65, 0x6d, 0x6f, 0x72,
+	0x79, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x70, 0x69, 0x64, 0x18, 0x0c,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x70, 0x70, 0x69, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x49, 0x64, 0x12, 0x2c, 0x0a, 0x12, 0x69, 0x6e, 0x74,
+	0x65, 0x72, 0x76, 0x61, 0x6c, 0x5f, 0x63, 0x70, 0x75, 0x5f, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18,
+	0x0e, 0x20, 0x01, 0x28, 0x01, 0x52, 0x10, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x43,
+	0x70, 0x75, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x73, 0x73, 0x5f, 0x62,
+	0x79, 0x74, 0x65, 0x73, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x73, 0x73, 0x42,
+	0x79, 0x74, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x76, 0x6d, 0x73, 0x5f, 0x62, 0x79, 0x74, 0x65,
+	0x73, 0x18, 0x10, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x76, 0x6d, 0x73, 0x42, 0x79, 0x74, 0x65,
+	0x73, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x68, 0x72, 0x65, 0x61, 0x64, 0x73, 0x18, 0x11, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x07, 0x74, 0x68, 0x72, 0x65, 0x61, 0x64, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x6f,
+	0x70, 0x65, 0x6e, 0x5f, 0x66, 0x64, 0x73, 0x18, 0x12, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x6f,
+	0x70, 0x65, 0x6e, 0x46, 0x64, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x62,
+	0x79, 0x74, 0x65, 0x73, 0x18, 0x13, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x72, 0x65, 0x61, 0x64,
+	0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x77, 0x72, 0x69, 0x74, 0x65, 0x5f, 0x62,
+	0x79, 0x74, 0x65, 0x73, 0x18, 0x14, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x77, 0x72, 0x69, 0x74,
+	0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x34, 0x0a, 0x16, 0x76, 0x6f, 0x6c, 0x75, 0x6e, 0x74,
+	0x61, 0x72, 0x79, 0x5f, 0x63, 0x74, 0x78, 0x5f, 0x73, 0x77, 0x69, 0x74, 0x63, 0x68, 0x65, 0x73,
+	0x18, 0x15, 0x20, 0x01, 0x28, 0x03, 0x52, 0x14, 0x76, 0x6f, 0x6c, 0x75, 0x6e, 0x74, 0x61, 0x72,
+	0x79, 0x43, 0x74, 0x78, 0x53, 0x77, 0x69, 0x74, 0x63, 0x68, 0x65, 0x73, 0x12, 0x38, 0x0a, 0x18,
+	0x69, 0x6e, 0x76, 0x6f, 0x6c, 0x75, 0x6e, 0x74, 0x61, 0x72, 0x79, 0x5f, 0x63, 0x74, 0x78, 0x5f,
+	0x73, 0x77, 0x69, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18, 0x16, 0x20, 0x01, 0x28, 0x03, 0x52, 0x16,
+	0x69, 0x6e, 0x76, 0x6f, 0x6c, 0x75, 0x6e, 0x74, 0x61, 0x72, 0x79, 0x43, 0x74, 0x78, 0x53, 0x77,
+	0x69, 0x74, 0x63, 0x68, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6d, 0x64, 0x6c, 0x69, 0x6e,
+	0x65, 0x18, 0x17, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6d, 0x64, 0x6c, 0x69, 0x6e, 0x65,
+	0x12, 0x10, 0x0a, 0x03, 0x65, 0x78, 0x65, 0x18, 0x18, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x65,
+	0x78, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x77, 0x64, 0x18, 0x19, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x63, 0x77, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x1a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x63, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x63, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6e, 0x74,
+	0x61, 0x69, 0x6e, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x73,
+	0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x5f, 0x75, 0x6e, 0x69, 0x74, 0x18, 0x1d, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x55, 0x6e, 0x69, 0x74, 0x12, 0x17,
+	0x0a, 0x07, 0x70, 0x6f, 0x64, 0x5f, 0x75, 0x69, 0x64, 0x18, 0x1e, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x70, 0x6f, 0x64, 0x55, 0x69, 0x64, 0x22, 0xc3, 0x03, 0x0a, 0x0d, 0x53, 0x79, 0x73, 0x74,
+	0x65, 0x6d, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x74, 0x6f,
+	0x72, 0x65, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a,
+	0x73, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x70,
+	0x75, 0x5f, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x63,
+	0x70, 0x75, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x61, 0x64, 0x31,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x6c, 0x6f, 0x61, 0x64, 0x31, 0x12, 0x14, 0x0a,
+	0x05, 0x6c, 0x6f, 0x61, 0x64, 0x35, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x6c, 0x6f,
+	0x61, 0x64, 0x35, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x61, 0x64, 0x31, 0x35, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x06, 0x6c, 0x6f, 0x61, 0x64, 0x31, 0x35, 0x12, 0x21, 0x0a, 0x0c, 0x6d,
+	0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0b, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x1f,
+	0x0a, 0x0b, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x75, 0x73, 0x65, 0x64, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0a, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x55, 0x73, 0x65, 0x64, 0x12,
+	0x1d, 0x0a, 0x0a, 0x73, 0x77, 0x61, 0x70, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x77, 0x61, 0x70, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x1b,
+	0x0a, 0x09, 0x73, 0x77, 0x61, 0x70, 0x5f, 0x75, 0x73, 0x65, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x08, 0x73, 0x77, 0x61, 0x70, 0x55, 0x73, 0x65, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x64,
+	0x69, 0x73, 0x6b, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x09, 0x64, 0x69, 0x73, 0x6b, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x69,
+	0x73, 0x6b, 0x5f, 0x75, 0x73, 0x65, 0x64, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x64,
+	0x69, 0x73, 0x6b, 0x55, 0x73, 0x65, 0x64, 0x12, 0x30, 0x0a, 0x14, 0x6e, 0x65, 0x74, 0x77, 0x6f,
+	0x72, 0x6b, 0x5f, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18,
+	0x0d, 0x20, 0x01, 0x28, 0x01, 0x52, 0x12, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x52, 0x65,
+	0x63, 0x65, 0x69, 0x76, 0x65, 0x52, 0x61, 0x74, 0x65, 0x12, 0x32, 0x0a, 0x15, 0x6e, 0x65, 0x74,
+	0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6d, 0x69, 0x74, 0x5f, 0x72, 0x61,
+	0x74, 0x65, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x01, 0x52, 0x13, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72,
+	0x6b, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x6d, 0x69, 0x74, 0x52, 0x61, 0x74, 0x65, 0x22, 0x72, 0x0a,
+	0x13, 0x53, 0x65, 0x6e, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x2b, 0x0a, 0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x73, 0x12, 0x25, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x48, 0x00, 0x52,
+	0x04, 0x61, 0x75, 0x74, 0x68, 0x88, 0x01, 0x01, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x61, 0x75, 0x74,
+	0x68, 0x22, 0x75, 0x0a, 0x14, 0x53, 0x65, 0x6e, 0x64, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73,
+	0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2d, 0x0a, 0x09, 0x70, 0x72, 0x6f,
+	0x63, 0x65, 0x73, 0x73, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x52, 0x09, 0x70,
+	0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x73, 0x12, 0x25, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x68,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e,
+	0x41, 0x75, 0x74, 0x68, 0x48, 0x00, 0x52, 0x04, 0x61, 0x75, 0x74, 0x68, 0x88, 0x01, 0x01, 0x42,
+	0x07, 0x0a, 0x05, 0x5f, 0x61, 0x75, 0x74, 0x68, 0x22, 0x7b, 0x0a, 0x18, 0x53, 0x65, 0x6e, 0x64,
+	0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x2f, 0x0a, 0x07, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x53,
+	0x79, 0x73, 0x74, 0x65, 0x6d, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52, 0x07, 0x6d, 0x65,
+	0x74, 0x72, 0x69, 0x63, 0x73, 0x12, 0x25, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x68, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x75, 0x74,
+	0x68, 0x48, 0x00, 0x52, 0x04, 0x61, 0x75, 0x74, 0x68, 0x88, 0x01, 0x01, 0x42, 0x07, 0x0a, 0x05,
+	0x5f, 0x61, 0x75, 0x74, 0x68, 0x32, 0xed, 0x01, 0x0a, 0x10, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63,
+	0x74, 0x6f, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x43, 0x0a, 0x0c, 0x53, 0x65,
+	0x6e, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x1b, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12,
+	0x45, 0x0a, 0x0d, 0x53, 0x65, 0x6e, 0x64, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x73,
+	0x12, 0x1c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x50, 0x72,
+	0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x4d, 0x0a, 0x11, 0x53, 0x65, 0x6e, 0x64, 0x53, 0x79,
+	0x73, 0x74, 0x65, 0x6d, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x12, 0x20, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x4d,
+	0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x42, 0x39, 0x0a, 0x0a, 0x67, 0x65, 0x6e, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x76, 0x31, 0x50, 0x01, 0x5a, 0x29, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x64, 0x65, 0x76, 0x7a, 0x65, 0x72, 0x6f, 0x2d, 0x69, 0x6e, 0x63, 0x2f, 0x6f, 0x64,
+	0x61, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x3b, 0x67, 0x65, 0x6e,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -578,29 +1139,35 @@ func file_api_v1_collector_proto_rawDescGZIP() []byte {
 	return file_api_v1_collector_proto_rawDescData
 }
 
-var file_api_v1_collector_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_api_v1_collector_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_api_v1_collector_proto_goTypes = []interface{}{
-	(*Auth)(nil),                 // 0: api.v1.Auth
-	(*Command)(nil),              // 1: api.v1.Command
-	(*Process)(nil),              // 2: api.v1.Process
-	(*SendCommandsRequest)(nil),  // 3: api.v1.SendCommandsRequest
-	(*SendProcessesRequest)(nil), // 4: api.v1.SendProcessesRequest
-	(*emptypb.Empty)(nil),        // 5: google.protobuf.Empty
+	(*Auth)(nil),                     // 0: api.v1.Auth
+	(*Command)(nil),                  // 1: api.v1.Command
+	(*Process)(nil),                  // 2: api.v1.Process
+	(*SystemMetrics)(nil),            // 3: api.v1.SystemMetrics
+	(*SendCommandsRequest)(nil),      // 4: api.v1.SendCommandsRequest
+	(*SendProcessesRequest)(nil),     // 5: api.v1.SendProcessesRequest
+	(*SendSystemMetricsRequest)(nil), // 6: api.v1.SendSystemMetricsRequest
+	(*emptypb.Empty)(nil),            // 7: google.protobuf.Empty
 }
 var file_api_v1_collector_proto_depIdxs = []int32{
 	1, // 0: api.v1.SendCommandsRequest.commands:type_name -> api.v1.Command
 	0, // 1: api.v1.SendCommandsRequest.auth:type_name -> api.v1.Auth
 	2, // 2: api.v1.SendProcessesRequest.processes:type_name -> api.v1.Process
 	0, // 3: api.v1.SendProcessesRequest.auth:type_name -> api.v1.Auth
-	3, // 4: api.v1.CollectorService.SendCommands:input_type -> api.v1.SendCommandsRequest
-	4, // 5: api.v1.CollectorService.SendProcesses:input_type -> api.v1.SendProcessesRequest
-	5, // 6: api.v1.CollectorService.SendCommands:output_type -> google.protobuf.Empty
-	5, // 7: api.v1.CollectorService.SendProcesses:output_type -> google.protobuf.Empty
-	6, // [6:8] is the sub-list for method output_type
-	4, // [4:6] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	3, // 4: api.v1.SendSystemMetricsRequest.metrics:type_name -> api.v1.SystemMetrics
+	0, // 5: api.v1.SendSystemMetricsRequest.auth:type_name -> api.v1.Auth
+	4, // 6: api.v1.CollectorService.SendCommands:input_type -> api.v1.SendCommandsRequest
+	5, // 7: api.v1.CollectorService.SendProcesses:input_type -> api.v1.SendProcessesRequest
+	6, // 8: api.v1.CollectorService.SendSystemMetrics:input_type -> api.v1.SendSystemMetricsRequest
+	7, // 9: api.v1.CollectorService.SendCommands:output_type -> google.protobuf.Empty
+	7, // 10: api.v1.CollectorService.SendProcesses:output_type -> google.protobuf.Empty
+	7, // 11: api.v1.CollectorService.SendSystemMetrics:output_type -> google.protobuf.Empty
+	9, // [9:12] is the sub-list for method output_type
+	6, // [6:9] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_api_v1_collector_proto_init() }
@@ -646,7 +1213,7 @@ func file_api_v1_collector_proto_init() {
 			}
 		}
 		file_api_v1_collector_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SendCommandsRequest); i {
+			switch v := v.(*SystemMetrics); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -658,6 +1225,18 @@ func file_api_v1_collector_proto_init() {
 			}
 		}
 		file_api_v1_collector_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SendCommandsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1_collector_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*SendProcessesRequest); i {
 			case 0:
 				return &v.state
@@ -669,17 +1248,31 @@ func file_api_v1_collector_proto_init() {
 				return nil
 			}
 		}
+		file_api_v1_collector_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SendSystemMetricsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_api_v1_collector_proto_msgTypes[0].OneofWrappers = []interface{}{}
-	file_api_v1_collector_proto_msgTypes[3].OneofWrappers = []interface{}{}
+	file_api_v1_collector_proto_msgTypes[1].OneofWrappers = []interface{}{}
 	file_api_v1_collector_proto_msgTypes[4].OneofWrappers = []interface{}{}
+	file_api_v1_collector_proto_msgTypes[5].OneofWrappers = []interface{}{}
+	file_api_v1_collector_proto_msgTypes[6].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_api_v1_collector_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -21,8 +21,9 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	CollectorService_SendCommands_FullMethodName  = "/api.v1.CollectorService/SendCommands"
-	CollectorService_SendProcesses_FullMethodName = "/api.v1.CollectorService/SendProcesses"
+	CollectorService_SendCommands_FullMethodName      = "/api.v1.CollectorService/SendCommands"
+	CollectorService_SendProcesses_FullMethodName     = "/api.v1.CollectorService/SendProcesses"
+	CollectorService_SendSystemMetrics_FullMethodName = "/api.v1.CollectorService/SendSystemMetrics"
 )
 
 // CollectorServiceClient is the client API for CollectorService service.
@@ -33,6 +34,8 @@ type CollectorServiceClient interface {
 	SendCommands(ctx context.Context, in *SendCommandsRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// RPC method for sending process data.
 	SendProcesses(ctx context.Context, in *SendProcessesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// RPC method for sending host-level system metrics.
+	SendSystemMetrics(ctx context.Context, in *SendSystemMetricsRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type collectorServiceClient struct {
@@ -61,6 +64,15 @@ func (c *collectorServiceClient) SendProcesses(ctx context.Context, in *SendProc
 	return out, nil
 }
 
+func (c *collectorServiceClient) SendSystemMetrics(ctx context.Context, in *SendSystemMetricsRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, CollectorService_SendSystemMetrics_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CollectorServiceServer is the server API for CollectorService service.
 // All implementations must embed UnimplementedCollectorServiceServer
 // for forward compatibility
@@ -69,6 +81,8 @@ type CollectorServiceServer interface {
 	SendCommands(context.Context, *SendCommandsRequest) (*emptypb.Empty, error)
 	// RPC method for sending process data.
 	SendProcesses(context.Context, *SendProcessesRequest) (*emptypb.Empty, error)
+	// RPC method for sending host-level system metrics.
+	SendSystemMetrics(context.Context, *SendSystemMetricsRequest) (*emptypb.Empty, error)
 	mustEmbedUnimplementedCollectorServiceServer()
 }
 
@@ -82,6 +96,9 @@ func (UnimplementedCollectorServiceServer) SendCommands(context.Context, *SendCo
 func (UnimplementedCollectorServiceServer) SendProcesses(context.Context, *SendProcessesRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendProcesses not implemented")
 }
+func (UnimplementedCollectorServiceServer) SendSystemMetrics(context.Context, *SendSystemMetricsRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendSystemMetrics not implemented")
+}
 func (UnimplementedCollectorServiceServer) mustEmbedUnimplementedCollectorServiceServer() {}
 
 // UnsafeCollectorServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -131,6 +148,24 @@ func _CollectorService_SendProcesses_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CollectorService_SendSystemMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendSystemMetricsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectorServiceServer).SendSystemMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectorService_SendSystemMetrics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectorServiceServer).SendSystemMetrics(ctx, req.(*SendSystemMetricsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CollectorService_ServiceDesc is the grpc.ServiceDesc for CollectorService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -146,6 +181,10 @@ var CollectorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SendProcesses",
 			Handler:    _CollectorService_SendProcesses_Handler,
 		},
+		{
+			MethodName: "SendSystemMetrics",
+			Handler:    _CollectorService_SendSystemMetrics_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/v1/collector.proto",
@@ -41,6 +41,9 @@ const (
 	// CollectorServiceSendProcessesProcedure is the fully-qualified name of the CollectorService's
 	// SendProcesses RPC.
 	CollectorServiceSendProcessesProcedure = "/api.v1.CollectorService/SendProcesses"
+	// CollectorServiceSendSystemMetricsProcedure is the fully-qualified name of the CollectorService's
+	// SendSystemMetrics RPC.
+	CollectorServiceSendSystemMetricsProcedure = "/api.v1.CollectorService/SendSystemMetrics"
 )
 
 // CollectorServiceClient is a client for the api.v1.CollectorService service.
@@ -49,6 +52,8 @@ type CollectorServiceClient interface {
 	SendCommands(context.Context, *connect.Request[v1.SendCommandsRequest]) (*connect.Response[emptypb.Empty], error)
 	// RPC method for sending process data.
 	SendProcesses(context.Context, *connect.Request[v1.SendProcessesRequest]) (*connect.Response[emptypb.Empty], error)
+	// RPC method for sending host-level system metrics.
+	SendSystemMetrics(context.Context, *connect.Request[v1.SendSystemMetricsRequest]) (*connect.Response[emptypb.Empty], error)
 }
 
 // NewCollectorServiceClient constructs a client for the api.v1.CollectorService service. By
@@ -71,13 +76,19 @@ func NewCollectorServiceClient(httpClient connect.HTTPClient, baseURL string, op
 			baseURL+CollectorServiceSendProcessesProcedure,
 			opts...,
 		),
+		sendSystemMetrics: connect.NewClient[v1.SendSystemMetricsRequest, emptypb.Empty](
+			httpClient,
+			baseURL+CollectorServiceSendSystemMetricsProcedure,
+			opts...,
+		),
 	}
 }
 
 // collectorServiceClient implements CollectorServiceClient.
 type collectorServiceClient struct {
-	sendCommands  *connect.Client[v1.SendCommandsRequest, emptypb.Empty]
-	sendProcesses *connect.Client[v1.SendProcessesRequest, emptypb.Empty]
+	sendCommands      *connect.Client[v1.SendCommandsRequest, emptypb.Empty]
+	sendProcesses     *connect.Client[v1.SendProcessesRequest, emptypb.Empty]
+	sendSystemMetrics *connect.Client[v1.SendSystemMetricsRequest, emptypb.Empty]
 }
 
 // SendCommands calls api.v1.CollectorService.SendCommands.
@@ -90,12 +101,19 @@ func (c *collectorServiceClient) SendProcesses(ctx context.Context, req *connect
 	return c.sendProcesses.CallUnary(ctx, req)
 }
 
+// SendSystemMetrics calls api.v1.CollectorService.SendSystemMetrics.
+func (c *collectorServiceClient) SendSystemMetrics(ctx context.Context, req *connect.Request[v1.SendSystemMetricsRequest]) (*connect.Response[emptypb.Empty], error) {
+	return c.sendSystemMetrics.CallUnary(ctx, req)
+}
+
 // CollectorServiceHandler is an implementation of the api.v1.CollectorService service.
 type CollectorServiceHandler interface {
 	// RPC method for sending command data.
 	SendCommands(context.Context, *connect.Request[v1.SendCommandsRequest]) (*connect.Response[emptypb.Empty], error)
 	// RPC method for sending process data.
 	SendProcesses(context.Context, *connect.Request[v1.SendProcessesRequest]) (*connect.Response[emptypb.Empty], error)
+	// RPC method for sending host-level system metrics.
+	SendSystemMetrics(context.Context, *connect.Request[v1.SendSystemMetricsRequest]) (*connect.Response[emptypb.Empty], error)
 }
 
 // NewCollectorServiceHandler builds an HTTP handler from the service implementation. It returns the
@@ -114,12 +132,19 @@ func NewCollectorServiceHandler(svc CollectorServiceHandler, opts ...connect.Han
 		svc.SendProcesses,
 		opts...,
 	)
+	collectorServiceSendSystemMetricsHandler := connect.NewUnaryHandler(
+		CollectorServiceSendSystemMetricsProcedure,
+		svc.SendSystemMetrics,
+		opts...,
+	)
 	return "/api.v1.CollectorService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case CollectorServiceSendCommandsProcedure:
 			collectorServiceSendCommandsHandler.ServeHTTP(w, r)
 		case CollectorServiceSendProcessesProcedure:
 			collectorServiceSendProcessesHandler.ServeHTTP(w, r)
+		case CollectorServiceSendSystemMetricsProcedure:
+			collectorServiceSendSystemMetricsHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -136,3 +161,7 @@ func (UnimplementedCollectorServiceHandler) SendCommands(context.Context, *conne
 func (UnimplementedCollectorServiceHandler) SendProcesses(context.Context, *connect.Request[v1.SendProcessesRequest]) (*connect.Response[emptypb.Empty], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("api.v1.CollectorService.SendProcesses is not implemented"))
 }
+
+func (UnimplementedCollectorServiceHandler) SendSystemMetrics(context.Context, *connect.Request[v1.SendSystemMetricsRequest]) (*connect.Response[emptypb.Empty], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("api.v1.CollectorService.SendSystemMetrics is not implemented"))
+}
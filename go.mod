@@ -1,6 +1,7 @@
 module github.com/devzero-inc/oda
 
-go 1.21.4
+go 1.23.0
+
 toolchain go1.23.7
 
 require (
@@ -16,6 +17,7 @@ require (
 	github.com/spf13/viper v1.18.2
 	github.com/stretchr/testify v1.9.0
 	golang.org/x/oauth2 v0.15.0
+	golang.org/x/sys v0.30.0
 	google.golang.org/grpc v1.59.0
 	google.golang.org/protobuf v1.34.2
 	modernc.org/sqlite v1.33.1
@@ -54,7 +56,6 @@ require (
 	go.uber.org/multierr v1.9.0 // indirect
 	golang.org/x/exp v0.0.0-20231108232855-2478ac86f678 // indirect
 	golang.org/x/net v0.36.0 // indirect
-	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20231120223509-83a465c0220f // indirect
@@ -0,0 +1,91 @@
+package host
+
+import (
+	"time"
+
+	"github.com/devzero-inc/oda/database"
+	gen "github.com/devzero-inc/oda/gen/api/v1"
+)
+
+// Sampler interface for host metrics collection
+type Sampler interface {
+	Collect() (Metrics, error)
+}
+
+// Metrics is the model for a sample of the host-level system metrics
+type Metrics struct {
+	Id         int64 `json:"id" db:"id"`
+	StoredTime int64 `json:"stored_time" db:"stored_time"`
+	// CPUUsage is the CPU usage percentage of the host since the previous sample across all CPUs, since boot for the first sample
+	CPUUsage float64 `json:"cpu_usage" db:"cpu_usage"`
+	Load1    float64 `json:"load1" db:"load1"`
+	Load5    float64 `json:"load5" db:"load5"`
+	Load15   float64 `json:"load15" db:"load15"`
+	// MemoryTotal and MemoryUsed are in bytes, the used memory excludes the caches the kernel can reclaim
+	MemoryTotal int64 `json:"memory_total" db:"memory_total"`
+	MemoryUsed  int64 `json:"memory_used" db:"memory_used"`
+	SwapTotal   int64 `json:"swap_total" db:"swap_total"`
+	SwapUsed    int64 `json:"swap_used" db:"swap_used"`
+	// DiskTotal and DiskUsed are the size and used space in bytes of the filesystem of the configured disk path
+	DiskTotal int64 `json:"disk_total" db:"disk_total"`
+	DiskUsed  int64 `json:"disk_used" db:"disk_used"`
+	// NetworkReceiveRate and NetworkTransmitRate are in bytes per second over all interfaces except loopback, 0 for the first sample
+	NetworkReceiveRate  float64 `json:"network_receive_rate" db:"network_receive_rate"`
+	NetworkTransmitRate float64 `json:"network_transmit_rate" db:"network_transmit_rate"`
+}
+
+// GetMetricsForPeriod fetches the host metrics samples for a given period ordered by time
+func GetMetricsForPeriod(start int64, end int64) ([]*Metrics, error) {
+	var metrics []*Metrics
+
+	err := database.DB.Select(&metrics, "SELECT * FROM system_metrics WHERE stored_time BETWEEN ? AND ? ORDER BY stored_time", start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
+// DeleteMetricsByDays deletes records older than n days
+func DeleteMetricsByDays(days int) error {
+	// Calculate the time when old records will be deleted
+	timeToDelete := time.Now().AddDate(0, 0, -days).UnixMilli()
+
+	result, err := database.DB.Exec("DELETE FROM system_metrics WHERE stored_time < ?", timeToDelete)
+	if err != nil {
+		return err
+	}
+
+	_, err = result.RowsAffected()
+
+	return err
+}
+
+// InsertMetrics inserts a host metrics sample into the database
+func InsertMetrics(metrics Metrics) error {
+	query := `INSERT INTO system_metrics (stored_time, cpu_usage, load1, load5, load15, memory_total, memory_used, swap_total, swap_used, disk_total, disk_used, network_receive_rate, network_transmit_rate)
+	VALUES (:stored_time, :cpu_usage, :load1, :load5, :load15, :memory_total, :memory_used, :swap_total, :swap_used, :disk_total, :disk_used, :network_receive_rate, :network_transmit_rate)`
+
+	_, err := database.DB.NamedExec(query, metrics)
+
+	return err
+}
+
+func MapMetricsToProto(metrics Metrics) *gen.SystemMetrics {
+	return &gen.SystemMetrics{
+		Id:                  metrics.Id,
+		StoredTime:          metrics.StoredTime,
+		CpuUsage:            metrics.CPUUsage,
+		Load1:               metrics.Load1,
+		Load5:               metrics.Load5,
+		Load15:              metrics.Load15,
+		MemoryTotal:         metrics.MemoryTotal,
+		MemoryUsed:          metrics.MemoryUsed,
+		SwapTotal:           metrics.SwapTotal,
+		SwapUsed:            metrics.SwapUsed,
+		DiskTotal:           metrics.DiskTotal,
+		DiskUsed:            metrics.DiskUsed,
+		NetworkReceiveRate:  metrics.NetworkReceiveRate,
+		NetworkTransmitRate: metrics.NetworkTransmitRate,
+	}
+}
@@ -0,0 +1,52 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/devzero-inc/oda/config"
+	"github.com/devzero-inc/oda/database"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTestDatabase(t *testing.T) {
+	t.Helper()
+
+	config.SetupSysConfig()
+
+	db, err := sqlx.Connect("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to connect to the database: %s", err)
+	}
+	// Every connection to an in-memory database gets its own database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	database.DB = db
+	database.RunMigrations()
+}
+
+func TestMetricsPersistence(t *testing.T) {
+	setupTestDatabase(t)
+
+	assert.NoError(t, InsertMetrics(Metrics{StoredTime: 2000, CPUUsage: 50, Load1: 1.5, MemoryTotal: 4096, MemoryUsed: 1024}))
+	assert.NoError(t, InsertMetrics(Metrics{StoredTime: 1000, CPUUsage: 25, DiskTotal: 8192, DiskUsed: 2048}))
+	assert.NoError(t, InsertMetrics(Metrics{StoredTime: 5000, CPUUsage: 75}))
+
+	metrics, err := GetMetricsForPeriod(1000, 2000)
+	assert.NoError(t, err)
+	if !assert.Len(t, metrics, 2) {
+		return
+	}
+
+	assert.Equal(t, int64(1000), metrics[0].StoredTime, "Samples should be ordered by time")
+	assert.Equal(t, int64(2048), metrics[0].DiskUsed)
+	assert.Equal(t, 50.0, metrics[1].CPUUsage)
+	assert.Equal(t, 1.5, metrics[1].Load1)
+	assert.Equal(t, int64(1024), metrics[1].MemoryUsed)
+
+	proto := MapMetricsToProto(*metrics[1])
+	assert.Equal(t, int64(2000), proto.StoredTime)
+	assert.Equal(t, int64(4096), proto.MemoryTotal)
+}
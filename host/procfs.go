@@ -0,0 +1,262 @@
+package host
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultProcRoot is the mount point of the proc filesystem
+const DefaultProcRoot = "/proc"
+
+// Procfs is the type for the host metrics sampler reading the proc filesystem, available on Linux only
+type Procfs struct {
+	logger zerolog.Logger
+	// root is the mount point of the proc filesystem, it can point to a fixture tree in tests
+	root string
+	// diskPath is a path on the filesystem whose space is sampled
+	diskPath string
+
+	// mutex guards the counters of the previous sample, the usage and rates are computed from their deltas
+	mutex    sync.Mutex
+	previous *counters
+}
+
+// counters are the cumulative counters of a sample
+type counters struct {
+	sampledAt time.Time
+	// cpuTotal and cpuIdle are the total and idle CPU times in clock ticks summed over all CPUs
+	cpuTotal uint64
+	cpuIdle  uint64
+	// received and transmitted are the bytes over all interfaces except loopback
+	received    uint64
+	transmitted uint64
+}
+
+// NewProcfs creates a new Procfs instance reading the proc filesystem mounted at root, /proc if empty,
+// and sampling the space of the filesystem containing diskPath, / if empty
+func NewProcfs(logger zerolog.Logger, root string, diskPath string) *Procfs {
+	if root == "" {
+		root = DefaultProcRoot
+	}
+	if diskPath == "" {
+		diskPath = "/"
+	}
+
+	return &Procfs{
+		logger:   logger,
+		root:     root,
+		diskPath: diskPath,
+	}
+}
+
+// Collect collects the host metrics from the proc filesystem
+func (p *Procfs) Collect() (Metrics, error) {
+	return p.sample(time.Now())
+}
+
+// sample collects the host metrics at the given time
+func (p *Procfs) sample(now time.Time) (Metrics, error) {
+	p.logger.Debug().Msg("Collecting host metrics")
+
+	metrics := Metrics{StoredTime: now.UnixMilli()}
+	current := &counters{sampledAt: now}
+
+	var err error
+	current.cpuTotal, current.cpuIdle, err = p.readCPU()
+	if err != nil {
+		return metrics, err
+	}
+
+	if err := p.readLoad(&metrics); err != nil {
+		return metrics, err
+	}
+
+	if err := p.readMemory(&metrics); err != nil {
+		return metrics, err
+	}
+
+	// Network and disk are not available in every environment, e.g. in sandboxes, so their errors are not fatal
+	current.received, current.transmitted, err = p.readNetwork()
+	if err != nil {
+		p.logger.Debug().Err(err).Msg("Failed to read network counters")
+	}
+
+	metrics.DiskTotal, metrics.DiskUsed, err = diskUsage(p.diskPath)
+	if err != nil {
+		p.logger.Debug().Err(err).Str("path", p.diskPath).Msg("Failed to read disk usage")
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	previous := p.previous
+	if previous == nil {
+		// The first sample has nothing to compare to, so the CPU usage is averaged since boot
+		previous = &counters{}
+	}
+
+	if current.cpuTotal > previous.cpuTotal {
+		total := current.cpuTotal - previous.cpuTotal
+		// I/O wait time can go backwards on some kernels, so the idle time is clamped
+		var idle uint64
+		if current.cpuIdle > previous.cpuIdle {
+			idle = min(current.cpuIdle-previous.cpuIdle, total)
+		}
+		metrics.CPUUsage = float64(total-idle) / float64(total) * 100
+	}
+
+	if elapsed := current.sampledAt.Sub(previous.sampledAt).Seconds(); p.previous != nil && elapsed > 0 {
+		// Counters go backwards when an interface is removed, the rate is left 0 for that sample
+		if current.received >= previous.received {
+			metrics.NetworkReceiveRate = float64(current.received-previous.received) / elapsed
+		}
+		if current.transmitted >= previous.transmitted {
+			metrics.NetworkTransmitRate = float64(current.transmitted-previous.transmitted) / elapsed
+		}
+	}
+
+	p.previous = current
+
+	return metrics, nil
+}
+
+// readCPU reads the total and idle CPU times from the aggregated line of /proc/stat
+func (p *Procfs) readCPU() (total uint64, idle uint64, err error) {
+	content, err := os.ReadFile(filepath.Join(p.root, "stat"))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		value, ok := strings.CutPrefix(line, "cpu ")
+		if !ok {
+			continue
+		}
+
+		// user nice system idle iowait irq softirq steal guest guest_nice, guest times are already part of user and nice
+		fields := strings.Fields(value)
+		if len(fields) < 4 {
+			return 0, 0, fmt.Errorf("invalid cpu line: %q", line)
+		}
+		if len(fields) > 8 {
+			fields = fields[:8]
+		}
+
+		for i, field := range fields {
+			ticks, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return 0, 0, fmt.Errorf("invalid cpu time: %w", err)
+			}
+
+			total += ticks
+			// Time waiting for I/O is idle time, the CPU could have run something else
+			if i == 3 || i == 4 {
+				idle += ticks
+			}
+		}
+
+		return total, idle, nil
+	}
+
+	return 0, 0, fmt.Errorf("cpu line not found in %s", filepath.Join(p.root, "stat"))
+}
+
+// readLoad reads the load averages from /proc/loadavg
+func (p *Procfs) readLoad(metrics *Metrics) error {
+	content, err := os.ReadFile(filepath.Join(p.root, "loadavg"))
+	if err != nil {
+		return err
+	}
+
+	fields := strings.Fields(string(content))
+	if len(fields) < 3 {
+		return fmt.Errorf("invalid load average: %q", content)
+	}
+
+	loads := make([]float64, 3)
+	for i := range loads {
+		loads[i], err = strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return fmt.Errorf("invalid load average: %w", err)
+		}
+	}
+	metrics.Load1, metrics.Load5, metrics.Load15 = loads[0], loads[1], loads[2]
+
+	return nil
+}
+
+// readMemory reads the memory and swap usage from /proc/meminfo
+func (p *Procfs) readMemory(metrics *Metrics) error {
+	content, err := os.ReadFile(filepath.Join(p.root, "meminfo"))
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]int64)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		kilobytes, _ := strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+		values[key] = kilobytes * 1024
+	}
+
+	available, ok := values["MemAvailable"]
+	if !ok {
+		// Kernels before 3.14 do not report the available memory, it is approximated by the free memory and caches
+		available = values["MemFree"] + values["Buffers"] + values["Cached"]
+	}
+
+	metrics.MemoryTotal = values["MemTotal"]
+	metrics.MemoryUsed = max(metrics.MemoryTotal-available, 0)
+	metrics.SwapTotal = values["SwapTotal"]
+	metrics.SwapUsed = max(metrics.SwapTotal-values["SwapFree"], 0)
+
+	return nil
+}
+
+// readNetwork reads the received and transmitted bytes over all interfaces except loopback from /proc/net/dev
+func (p *Procfs) readNetwork() (received uint64, transmitted uint64, err error) {
+	content, err := os.ReadFile(filepath.Join(p.root, "net", "dev"))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// The first two lines are headers, each following line is interface: receive fields, then transmit fields
+	for _, line := range strings.Split(string(content), "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) == "lo" {
+			continue
+		}
+
+		fields := strings.Fields(value)
+		if len(fields) < 9 {
+			continue
+		}
+
+		rx, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid received bytes: %w", err)
+		}
+		tx, err := strconv.ParseUint(fields[8], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid transmitted bytes: %w", err)
+		}
+
+		received += rx
+		transmitted += tx
+	}
+
+	return received, transmitted, nil
+}
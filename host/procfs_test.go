@@ -0,0 +1,85 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcfsCollectFixture(t *testing.T) {
+	procfs := NewProcfs(zerolog.Nop(), "testdata/proc", t.TempDir())
+
+	metrics, err := procfs.Collect()
+	assert.NoError(t, err, "Collect method should not return an error")
+
+	// The first sample has no previous counters, so the CPU usage is averaged since boot and there are no rates
+	assert.InDelta(t, 15.0, metrics.CPUUsage, 0.0001)
+	assert.Equal(t, 0.5, metrics.Load1)
+	assert.Equal(t, 1.25, metrics.Load5)
+	assert.Equal(t, 2.0, metrics.Load15)
+	assert.Equal(t, int64(1000000*1024), metrics.MemoryTotal)
+	assert.Equal(t, int64(400000*1024), metrics.MemoryUsed)
+	assert.Equal(t, int64(500000*1024), metrics.SwapTotal)
+	assert.Equal(t, int64(100000*1024), metrics.SwapUsed)
+	assert.Zero(t, metrics.NetworkReceiveRate)
+	assert.Zero(t, metrics.NetworkTransmitRate)
+	assert.NotZero(t, metrics.StoredTime)
+}
+
+func TestProcfsCollectDeltas(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"stat", "loadavg", "meminfo", "net/dev"} {
+		content, err := os.ReadFile(filepath.Join("testdata/proc", name))
+		assert.NoError(t, err)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(root, name), content, 0644))
+	}
+
+	procfs := NewProcfs(zerolog.Nop(), root, root)
+	start := time.Now()
+
+	_, err := procfs.sample(start)
+	assert.NoError(t, err)
+
+	// 1000 ticks passed, 250 of them idle or waiting for I/O, eth0 received 10000 and wlan0 sent 5000 bytes
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "stat"), []byte("cpu  1600 0 650 8200 550 0 0 0 0 0\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "net/dev"), []byte(`Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo: 9900000    1000    0    0    0     0          0         0  9900000    1000    0    0    0     0       0          0
+  eth0: 1010000    2000    0    0    0     0          0         0   400000    1500    0    0    0     0       0          0
+ wlan0:   24000     100    0    0    0     0          0         0    21000      80    0    0    0     0       0          0
+`), 0644))
+
+	metrics, err := procfs.sample(start.Add(10 * time.Second))
+	assert.NoError(t, err)
+
+	assert.InDelta(t, 75.0, metrics.CPUUsage, 0.0001)
+	assert.InDelta(t, 1000.0, metrics.NetworkReceiveRate, 0.0001, "Loopback traffic should not be counted")
+	assert.InDelta(t, 500.0, metrics.NetworkTransmitRate, 0.0001)
+}
+
+func TestProcfsCollectMissingRoot(t *testing.T) {
+	procfs := NewProcfs(zerolog.Nop(), "testdata/missing", "")
+
+	_, err := procfs.Collect()
+	assert.Error(t, err)
+}
+
+func TestProcfsCollectWithRealOutput(t *testing.T) {
+	if _, err := os.Stat(DefaultProcRoot + "/stat"); err != nil {
+		t.Skip("proc filesystem is not available")
+	}
+
+	procfs := NewProcfs(zerolog.Nop(), "", "")
+
+	metrics, err := procfs.Collect()
+
+	assert.NoError(t, err, "Collect method should not return an error")
+	assert.NotZero(t, metrics.MemoryTotal)
+	assert.NotZero(t, metrics.DiskTotal)
+	assert.LessOrEqual(t, metrics.DiskUsed, metrics.DiskTotal)
+}
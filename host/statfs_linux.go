@@ -0,0 +1,17 @@
+//go:build linux
+
+package host
+
+import "golang.org/x/sys/unix"
+
+// diskUsage returns the total and used bytes of the filesystem containing path
+func diskUsage(path string) (total int64, used int64, err error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+
+	blockSize := int64(stat.Bsize)
+
+	return int64(stat.Blocks) * blockSize, int64(stat.Blocks-stat.Bfree) * blockSize, nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package host
+
+import "errors"
+
+// diskUsage is not supported on other platforms than Linux
+func diskUsage(_ string) (int64, int64, error) {
+	return 0, 0, errors.New("disk usage is not supported on this platform")
+}
@@ -4,11 +4,12 @@ import (
 	"time"
 
 	"github.com/devzero-inc/oda/collector"
+	"github.com/devzero-inc/oda/host"
 	"github.com/devzero-inc/oda/process"
 )
 
 // Cleanup job that will run in background and every 'hours' try to run the ticker
-// and delete process and commands older than 'days'
+// and delete process, commands and host metrics older than 'days'
 func Cleanup(hours int, days int) {
 	// ticker to run cleanup every n hours
 	ticker := time.NewTicker(time.Duration(hours) * time.Hour)
@@ -18,7 +19,10 @@ func Cleanup(hours int, days int) {
 			select {
 			case <-ticker.C:
 				collector.DeleteCommandsByDays(days)
+				collector.DeleteSessionsByDays(days)
 				process.DeleteProcessesByDays(days)
+				process.DeleteRunsByDays(days)
+				host.DeleteMetricsByDays(days)
 			}
 		}
 	}()
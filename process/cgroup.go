@@ -0,0 +1,104 @@
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// containerIDPattern matches the container ID set by Docker, containerd, CRI-O and Podman in the cgroup path
+	containerIDPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+	// podUIDPattern matches the pod UID in the cgroup path of Kubernetes, the systemd driver replaces dashes with underscores
+	podUIDPattern = regexp.MustCompile(`pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12})`)
+)
+
+// cgroupInfo is the control group of a process and what is derived from its path
+type cgroupInfo struct {
+	path        string
+	containerID string
+	systemdUnit string
+	podUID      string
+}
+
+// readCgroup reads the control group of the process from the proc filesystem mounted at root,
+// an empty control group is returned when it can not be read, e.g. on other platforms than Linux
+func readCgroup(root string, pid int64) cgroupInfo {
+	content, err := os.ReadFile(filepath.Join(root, strconv.FormatInt(pid, 10), "cgroup"))
+	if err != nil {
+		return cgroupInfo{}
+	}
+
+	return parseCgroup(content)
+}
+
+// parseCgroup parses the content of /proc/[pid]/cgroup. The unified hierarchy of cgroup v2 is preferred,
+// with cgroup v1 the systemd hierarchy is used as it is present on all systemd hosts and in containers.
+func parseCgroup(content []byte) cgroupInfo {
+	var unified, systemd, first string
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		// Each line is hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 || parts[2] == "" {
+			continue
+		}
+
+		switch {
+		case parts[0] == "0" && parts[1] == "":
+			unified = parts[2]
+		case parts[1] == "name=systemd":
+			systemd = parts[2]
+		case first == "":
+			first = parts[2]
+		}
+	}
+
+	path := unified
+	if path == "" || path == "/" {
+		path = systemd
+	}
+	if path == "" {
+		path = first
+	}
+
+	return parseCgroupPath(path)
+}
+
+// parseCgroupPath derives the container ID, systemd unit and pod UID from the cgroup path, e.g.
+// /kubepods.slice/kubepods-pod<uid>.slice/cri-containerd-<id>.scope or /system.slice/docker-<id>.scope
+func parseCgroupPath(path string) cgroupInfo {
+	cgroup := cgroupInfo{path: path}
+
+	segments := strings.Split(path, "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment := segments[i]
+
+		if cgroup.containerID == "" {
+			cgroup.containerID = containerIDPattern.FindString(segment)
+		}
+		if cgroup.systemdUnit == "" && (strings.HasSuffix(segment, ".service") || strings.HasSuffix(segment, ".scope")) {
+			cgroup.systemdUnit = segment
+		}
+		if cgroup.podUID == "" {
+			if match := podUIDPattern.FindStringSubmatch(segment); match != nil {
+				cgroup.podUID = strings.ReplaceAll(match[1], "_", "-")
+			}
+		}
+	}
+
+	return cgroup
+}
+
+// setCgroup sets the control group fields of the process
+func (p *Process) setCgroup(cgroup cgroupInfo) {
+	p.Cgroup = cgroup.path
+	p.ContainerID = cgroup.containerID
+	p.SystemdUnit = cgroup.systemdUnit
+	p.PodUID = cgroup.podUID
+}
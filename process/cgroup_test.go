@@ -0,0 +1,59 @@
+package process
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testContainerID = "4f7a1c2b3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8"
+
+func TestParseCgroup(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    cgroupInfo
+	}{
+		{
+			name:    "systemd service",
+			content: "0::/system.slice/ssh.service\n",
+			want:    cgroupInfo{path: "/system.slice/ssh.service", systemdUnit: "ssh.service"},
+		},
+		{
+			name:    "docker with systemd driver",
+			content: "0::/system.slice/docker-" + testContainerID + ".scope\n",
+			want: cgroupInfo{
+				path:        "/system.slice/docker-" + testContainerID + ".scope",
+				containerID: testContainerID,
+				systemdUnit: "docker-" + testContainerID + ".scope",
+			},
+		},
+		{
+			name: "docker with cgroup v1",
+			content: "12:memory:/docker/" + testContainerID + "\n" +
+				"1:name=systemd:/docker/" + testContainerID + "\n" +
+				"0::/\n",
+			want: cgroupInfo{path: "/docker/" + testContainerID, containerID: testContainerID},
+		},
+		{
+			name:    "kubernetes with cgroupfs driver",
+			content: "0::/kubepods/burstable/pod0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0/" + testContainerID + "\n",
+			want: cgroupInfo{
+				path:        "/kubepods/burstable/pod0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0/" + testContainerID,
+				containerID: testContainerID,
+				podUID:      "0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0",
+			},
+		},
+		{
+			name:    "invalid",
+			content: "garbage\n",
+			want:    cgroupInfo{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseCgroup([]byte(tt.content)))
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package process
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cpuKey identifies a process across samples, the start time tells apart processes reusing a PID
+type cpuKey struct {
+	pid     int64
+	created int64
+}
+
+// cpuSample is the total CPU time of a process at the time it was sampled
+type cpuSample struct {
+	cpuSeconds float64
+	sampledAt  time.Time
+}
+
+// cpuTracker keeps the CPU time of the processes from the previous sample,
+// so the CPU usage can be computed over the interval between two samples.
+type cpuTracker struct {
+	mutex    sync.Mutex
+	previous map[cpuKey]cpuSample
+	current  map[cpuKey]cpuSample
+}
+
+// newCPUTracker creates a new cpuTracker instance
+func newCPUTracker() *cpuTracker {
+	return &cpuTracker{
+		previous: make(map[cpuKey]cpuSample),
+		current:  make(map[cpuKey]cpuSample),
+	}
+}
+
+// intervalUsage records the total CPU time of the process and returns its CPU usage percentage since the previous
+// sample. A process seen for the first time has no previous sample, so its lifetime usage is returned instead.
+func (t *cpuTracker) intervalUsage(pid int64, created int64, cpuSeconds float64, lifetimeUsage float64, sampledAt time.Time) float64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	key := cpuKey{pid: pid, created: created}
+	t.current[key] = cpuSample{cpuSeconds: cpuSeconds, sampledAt: sampledAt}
+
+	previous, ok := t.previous[key]
+	if !ok {
+		return lifetimeUsage
+	}
+
+	elapsed := sampledAt.Sub(previous.sampledAt).Seconds()
+	if elapsed <= 0 || cpuSeconds < previous.cpuSeconds {
+		return lifetimeUsage
+	}
+
+	return (cpuSeconds - previous.cpuSeconds) / elapsed * 100
+}
+
+// finishSample ends the current sample, processes that were not seen in it are forgotten
+func (t *cpuTracker) finishSample() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.previous = t.current
+	t.current = make(map[cpuKey]cpuSample, len(t.previous))
+}
+
+// parseCPUTime parses the cumulative CPU time printed by ps, [[dd-]hh:]mm:ss with optional fractional seconds
+func parseCPUTime(value string) (float64, bool) {
+	var days float64
+	if d, rest, ok := strings.Cut(value, "-"); ok {
+		parsed, err := strconv.ParseFloat(d, 64)
+		if err != nil {
+			return 0, false
+		}
+		days = parsed
+		value = rest
+	}
+
+	var seconds float64
+	for _, part := range strings.Split(value, ":") {
+		parsed, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, false
+		}
+		seconds = seconds*60 + parsed
+	}
+
+	return days*86400 + seconds, true
+}
@@ -0,0 +1,48 @@
+package process
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCPUTrackerIntervalUsage(t *testing.T) {
+	tracker := newCPUTracker()
+	start := time.UnixMilli(1_000_000)
+
+	// A long-running process with a low lifetime usage spikes between the samples
+	assert.Equal(t, 2.0, tracker.intervalUsage(10, 1, 100, 2, start))
+	assert.Equal(t, 5.0, tracker.intervalUsage(11, 1, 50, 5, start))
+	tracker.finishSample()
+
+	assert.Equal(t, 150.0, tracker.intervalUsage(10, 1, 107.5, 2.1, start.Add(5*time.Second)))
+	// A reused PID is a different process, it has no previous sample
+	assert.Equal(t, 3.0, tracker.intervalUsage(11, 2, 1, 3, start.Add(5*time.Second)))
+	tracker.finishSample()
+
+	// Processes missing from a sample are forgotten
+	assert.Equal(t, 4.0, tracker.intervalUsage(11, 1, 60, 4, start.Add(10*time.Second)))
+}
+
+func TestParseCPUTime(t *testing.T) {
+	tests := []struct {
+		value   string
+		seconds float64
+		ok      bool
+	}{
+		{"00:00:00", 0, true},
+		{"01:02:03", 3723, true},
+		{"2-01:02:03", 176523, true},
+		{"12:34", 754, true},
+		{"0:01.50", 1.5, true},
+		{"", 0, false},
+		{"x:00", 0, false},
+	}
+
+	for _, tt := range tests {
+		seconds, ok := parseCPUTime(tt.value)
+		assert.Equal(t, tt.ok, ok, tt.value)
+		assert.InDelta(t, tt.seconds, seconds, 0.0001, tt.value)
+	}
+}
@@ -3,10 +3,12 @@ package process
 import (
 	"errors"
 	"fmt"
+	"runtime"
 	"time"
 
 	"github.com/devzero-inc/oda/database"
 	gen "github.com/devzero-inc/oda/gen/api/v1"
+	"github.com/devzero-inc/oda/util"
 
 	"github.com/rs/zerolog"
 )
@@ -14,6 +16,7 @@ import (
 const (
 	PsutilType = "psutil"
 	PsType     = "ps"
+	ProcfsType = "procfs"
 )
 
 // SystemProcess interface for process collection
@@ -24,12 +27,18 @@ type SystemProcess interface {
 // Factory implementation for proc providers
 type Factory struct {
 	logger zerolog.Logger
+	// procRoot is the mount point of the proc filesystem used by the procfs collector
+	procRoot string
+	// goos is the operating system the collector runs on, the proc filesystem exists on Linux only
+	goos string
 }
 
 // NewFactory init Factory implementation for proc providers
-func NewFactory(logger zerolog.Logger) *Factory {
+func NewFactory(logger zerolog.Logger, procRoot string) *Factory {
 	return &Factory{
-		logger: logger,
+		logger:   logger,
+		procRoot: procRoot,
+		goos:     runtime.GOOS,
 	}
 }
 
@@ -40,6 +49,12 @@ func (f *Factory) Create(pType string) (SystemProcess, error) {
 		return NewPsutil(f.logger), nil
 	case PsType:
 		return NewPs(f.logger), nil
+	case ProcfsType:
+		if f.goos != "linux" {
+			f.logger.Warn().Str("os", f.goos).Msg("The procfs process collection is available on Linux only, falling back to ps")
+			return NewPs(f.logger), nil
+		}
+		return NewProcfs(f.logger, f.procRoot), nil
 	default:
 		return nil, errors.New("system process type not supported")
 	}
@@ -52,32 +67,103 @@ type Process struct {
 	PPID int64  `json:"ppid" db:"ppid"`
 	Name string `json:"name" db:"name"`
 	// R: Running; S: Sleep; T: Stop; I: Idle; Z: Zombie; W: Wait; L: Lock;
-	Status         string  `json:"status" db:"status"`
-	CreatedTime    int64   `json:"created_time" db:"created_time"`
-	StoredTime     int64   `json:"stored_time" db:"stored_time"`
-	OS             string  `json:"os" db:"os"`
-	Platform       string  `json:"platform" db:"platform"`
-	PlatformFamily string  `json:"platform_family" db:"platform_family"`
-	CPUUsage       float64 `json:"cpu_usage" db:"cpu_usage"`
-	MemoryUsage    float64 `json:"memory_usage" db:"memory_usage"`
+	Status         string `json:"status" db:"status"`
+	CreatedTime    int64  `json:"created_time" db:"created_time"`
+	StoredTime     int64  `json:"stored_time" db:"stored_time"`
+	OS             string `json:"os" db:"os"`
+	Platform       string `json:"platform" db:"platform"`
+	PlatformFamily string `json:"platform_family" db:"platform_family"`
+	// CPUUsage is the CPU usage percentage averaged over the lifetime of the process
+	CPUUsage float64 `json:"cpu_usage" db:"cpu_usage"`
+	// IntervalCPUUsage is the CPU usage percentage since the previous sample, the lifetime usage for the first sample
+	IntervalCPUUsage float64 `json:"interval_cpu_usage" db:"interval_cpu_usage"`
+	MemoryUsage      float64 `json:"memory_usage" db:"memory_usage"`
+	// RSSBytes and VMSBytes are the resident and virtual memory sizes in bytes
+	RSSBytes int64 `json:"rss_bytes" db:"rss_bytes"`
+	VMSBytes int64 `json:"vms_bytes" db:"vms_bytes"`
+	Threads  int64 `json:"threads" db:"threads"`
+	// OpenFDs is the number of open file descriptors, 0 if they can not be read
+	OpenFDs int64 `json:"open_fds" db:"open_fds"`
+	// ReadBytes and WriteBytes are the bytes read from and written to storage over the lifetime of the process
+	ReadBytes  int64 `json:"read_bytes" db:"read_bytes"`
+	WriteBytes int64 `json:"write_bytes" db:"write_bytes"`
+	// VoluntaryCtxSwitches and InvoluntaryCtxSwitches are the context switches over the lifetime of the process
+	VoluntaryCtxSwitches   int64 `json:"voluntary_ctx_switches" db:"voluntary_ctx_switches"`
+	InvoluntaryCtxSwitches int64 `json:"involuntary_ctx_switches" db:"involuntary_ctx_switches"`
+	// Cmdline is the full command line of the process, redacted before it is stored
+	Cmdline string `json:"cmdline" db:"cmdline"`
+	// Exe and Cwd are the executable path and working directory, empty if they can not be read
+	Exe string `json:"exe" db:"exe"`
+	Cwd string `json:"cwd" db:"cwd"`
+	// Username is the owner of the process, the user ID if the user is unknown
+	Username string `json:"username" db:"username"`
+	// Cgroup is the control group path of the process, empty on other platforms than Linux
+	Cgroup string `json:"cgroup" db:"cgroup"`
+	// ContainerID, SystemdUnit and PodUID are derived from the control group path, empty if it does not contain them
+	ContainerID string `json:"container_id" db:"container_id"`
+	SystemdUnit string `json:"systemd_unit" db:"systemd_unit"`
+	PodUID      string `json:"pod_uid" db:"pod_uid"`
+	// CommandID is the UUID of the command that spawned the process, empty if not attributed
+	CommandID string `json:"command_id" db:"command_id"`
+	// IdentityID is the ID of the process identity the sample belongs to, it tells apart processes that reused a PID
+	IdentityID int64 `json:"identity_id" db:"identity_id"`
+}
+
+// ContainerUsage is the resource usage of the processes of a single container, processes outside of
+// containers are grouped under an empty container ID
+type ContainerUsage struct {
+	ContainerID string `json:"container_id" db:"container_id"`
+	PodUID      string `json:"pod_uid" db:"pod_uid"`
+	// Processes is the number of distinct processes seen in the container
+	Processes int64 `json:"processes" db:"processes"`
+	// PeakCPUUsage and AvgCPUUsage are the highest and average interval CPU usage of the container's processes summed per sample
+	PeakCPUUsage float64 `json:"peak_cpu_usage" db:"peak_cpu_usage"`
+	AvgCPUUsage  float64 `json:"avg_cpu_usage" db:"avg_cpu_usage"`
+	// PeakMemoryUsage is the highest memory usage of the container's processes summed per sample
+	PeakMemoryUsage float64 `json:"peak_memory_usage" db:"peak_memory_usage"`
+}
+
+// processIdentity is the process with the hash of its command line, used to insert its identity
+type processIdentity struct {
+	Process
+	CmdlineHash string `db:"cmdline_hash"`
 }
 
 // GetAllProcessesForPeriod fetches all processes for a given period
 func GetAllProcessesForPeriod(start int64, end int64) ([]*Process, error) {
+	return getAllProcesses("stored_time BETWEEN ? AND ?", start, end)
+}
+
+// GetProcessesForCommand fetches all processes spawned by the command with the given UUID
+func GetProcessesForCommand(commandID string) ([]*Process, error) {
+	return getAllProcesses("command_id = ?", commandID)
+}
+
+// samplesTable is the samples joined with the identities of their processes, the conditions of the queries
+// refer to the columns of the samples
+const samplesTable = `process_samples s JOIN process_identities i ON i.id = s.identity_id`
+
+// getAllProcesses fetches the processes matching the condition, aggregated by process identity
+func getAllProcesses(condition string, args ...interface{}) ([]*Process, error) {
 	var processes []*Process
 
-	query := `SELECT pid, name, MAX(cpu_usage) as cpu_usage, MAX(memory_usage) as memory_usage
+	query := `SELECT i.id AS identity_id, i.pid, i.name, i.cmdline, i.exe, i.cwd, i.username,
+       top_samples.cpu_usage, top_samples.interval_cpu_usage, top_samples.memory_usage
 FROM (
-    SELECT pid, name, cpu_usage, memory_usage
-    FROM processes
-    WHERE stored_time BETWEEN ? AND ? 
-    ORDER BY cpu_usage DESC, memory_usage DESC
-    LIMIT 100
-) AS filtered_processes
-GROUP BY pid, name
-ORDER BY cpu_usage DESC, memory_usage DESC;`
-
-	err := database.DB.Select(&processes, query, start, end)
+    SELECT identity_id, MAX(cpu_usage) AS cpu_usage, MAX(interval_cpu_usage) AS interval_cpu_usage, MAX(memory_usage) AS memory_usage
+    FROM (
+        SELECT identity_id, cpu_usage, interval_cpu_usage, memory_usage
+        FROM process_samples
+        WHERE ` + condition + `
+        ORDER BY interval_cpu_usage DESC, memory_usage DESC
+        LIMIT 100
+    ) AS filtered_samples
+    GROUP BY identity_id
+) AS top_samples
+JOIN process_identities i ON i.id = top_samples.identity_id
+ORDER BY top_samples.interval_cpu_usage DESC, top_samples.memory_usage DESC;`
+
+	err := database.DB.Select(&processes, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -85,61 +171,134 @@ ORDER BY cpu_usage DESC, memory_usage DESC;`
 	return processes, nil
 }
 
+// GetContainerUsageForPeriod fetches the resource usage grouped by container for a given period
+func GetContainerUsageForPeriod(start int64, end int64) ([]*ContainerUsage, error) {
+	return getContainerUsage("stored_time BETWEEN ? AND ?", start, end)
+}
+
+// GetContainerUsageForCommand fetches the resource usage grouped by container of the processes spawned by the command
+func GetContainerUsageForCommand(commandID string) ([]*ContainerUsage, error) {
+	return getContainerUsage("command_id = ?", commandID)
+}
+
+// getContainerUsage fetches the resource usage of the processes matching the condition grouped by container
+func getContainerUsage(condition string, args ...interface{}) ([]*ContainerUsage, error) {
+	var usage []*ContainerUsage
+
+	query := `SELECT c.container_id, MAX(c.pod_uid) AS pod_uid, MAX(c.cpu_usage) AS peak_cpu_usage, AVG(c.cpu_usage) AS avg_cpu_usage,
+       MAX(c.memory_usage) AS peak_memory_usage,
+       (SELECT COUNT(DISTINCT s.identity_id) FROM ` + samplesTable + ` WHERE i.container_id = c.container_id AND s.` + condition + `) AS processes
+FROM (
+    SELECT i.container_id, MAX(i.pod_uid) AS pod_uid, s.stored_time, SUM(s.interval_cpu_usage) AS cpu_usage, SUM(s.memory_usage) AS memory_usage
+    FROM ` + samplesTable + `
+    WHERE s.` + condition + `
+    GROUP BY i.container_id, s.stored_time
+) AS c
+GROUP BY c.container_id
+ORDER BY peak_cpu_usage DESC;`
+
+	if err := database.DB.Select(&usage, query, append(args, args...)...); err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
 // GetTopProcessesAndMetrics fetches the top processes based on a criterion like average CPU usage,
-// and then fetches detailed time-series data for each top process.
+// and then fetches detailed time-series data for each top process keyed by its identity ID.
 func GetTopProcessesAndMetrics(start int64, end int64) (map[int64][]*Process, error) {
-	query := `SELECT p.name, p.pid, p.cpu_usage, p.memory_usage, p.stored_time
+	return getTopProcessesAndMetrics("stored_time BETWEEN ? AND ?", start, end)
+}
+
+// GetTopProcessesAndMetricsForCommand fetches the top processes spawned by the command with the given UUID,
+// and then fetches detailed time-series data for each top process.
+func GetTopProcessesAndMetricsForCommand(commandID string) (map[int64][]*Process, error) {
+	return getTopProcessesAndMetrics("command_id = ?", commandID)
+}
+
+// getTopProcessesAndMetrics fetches the time-series data of the top processes matching the condition,
+// processes are told apart by their identity so a reused PID does not merge two processes into one series
+func getTopProcessesAndMetrics(condition string, args ...interface{}) (map[int64][]*Process, error) {
+	query := `SELECT s.identity_id, i.name, i.pid, s.cpu_usage, s.interval_cpu_usage, s.memory_usage, s.stored_time
 FROM (
-    SELECT pid, name, MAX(cpu_usage) as cpu_usage, MAX(memory_usage) as memory_usage
+    SELECT identity_id, MAX(interval_cpu_usage) as interval_cpu_usage, MAX(memory_usage) as memory_usage
         FROM (
-            SELECT pid, name, cpu_usage, memory_usage
-            FROM processes
-            WHERE stored_time BETWEEN ? AND ? 
-            ORDER BY cpu_usage DESC, memory_usage DESC
+            SELECT identity_id, interval_cpu_usage, memory_usage
+            FROM process_samples
+            WHERE ` + condition + `
+            ORDER BY interval_cpu_usage DESC, memory_usage DESC
             LIMIT 100
-        ) AS filtered_processes
-    GROUP BY pid, name
-    ORDER BY cpu_usage DESC, memory_usage DESC
+        ) AS filtered_samples
+    GROUP BY identity_id
+    ORDER BY interval_cpu_usage DESC, memory_usage DESC
     LIMIT 20
 ) AS top_processes
-JOIN processes p ON top_processes.name = p.name AND top_processes.pid = p.pid
-WHERE p.stored_time BETWEEN ? AND ? 
-ORDER BY p.stored_time DESC;`
+JOIN process_samples s ON s.identity_id = top_processes.identity_id
+JOIN process_identities i ON i.id = s.identity_id
+WHERE s.` + condition + `
+ORDER BY s.stored_time DESC;`
 
 	var allMetrics []*Process
-	err := database.DB.Select(&allMetrics, query, start, end, start, end)
+	err := database.DB.Select(&allMetrics, query, append(args, args...)...)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching process metrics: %v", err)
 	}
 
-	// Organize the results into a map of PID to list of Process structs
+	// Organize the results into a map of identity ID to list of Process structs
 	processMetricsMap := make(map[int64][]*Process)
 	for _, metric := range allMetrics {
-		processMetricsMap[metric.PID] = append(processMetricsMap[metric.PID], metric)
+		processMetricsMap[metric.IdentityID] = append(processMetricsMap[metric.IdentityID], metric)
 	}
 
 	return processMetricsMap, nil
 }
 
-// DeleteProcessesByDays deletes records older than n days
+// GetCommandSamples fetches the resource usage of the command's process tree summed per sample, ordered by time
+func GetCommandSamples(commandID string) ([]*Process, error) {
+	var samples []*Process
+
+	query := `SELECT stored_time, SUM(cpu_usage) AS cpu_usage, SUM(interval_cpu_usage) AS interval_cpu_usage, SUM(memory_usage) AS memory_usage
+              FROM process_samples
+              WHERE command_id = ?
+              GROUP BY stored_time
+              ORDER BY stored_time ASC;`
+
+	if err := database.DB.Select(&samples, query, commandID); err != nil {
+		return nil, err
+	}
+
+	return samples, nil
+}
+
+// DeleteProcessesByDays deletes samples older than n days and the identities left without samples
 func DeleteProcessesByDays(days int) error {
 	// Calculate the time when old records will be deleted
-	timeToDelete := time.Now().AddDate(0, 0, -days).Unix()
+	timeToDelete := time.Now().AddDate(0, 0, -days).UnixMilli()
 
-	result, err := database.DB.Exec("DELETE FROM processes WHERE stored_time < ?", timeToDelete)
+	result, err := database.DB.Exec("DELETE FROM process_samples WHERE stored_time < ?", timeToDelete)
 	if err != nil {
 		return err
 	}
 
-	_, err = result.RowsAffected()
+	if _, err := result.RowsAffected(); err != nil {
+		return err
+	}
+
+	_, err = database.DB.Exec("DELETE FROM process_identities WHERE NOT EXISTS (SELECT 1 FROM process_samples WHERE identity_id = process_identities.id)")
 
 	return err
 }
 
-// InsertProcesses inserts multiple processes into the database in bulk
+// InsertProcesses inserts multiple processes into the database in bulk, the identity of each process is
+// created when it is first seen and every sample references it
 func InsertProcesses(processes []Process) error {
-	query := `INSERT INTO processes (pid, name, status, created_time, stored_time, os, platform, platform_family, cpu_usage, memory_usage, ppid)
-	VALUES (:pid, :name, :status, :created_time, :stored_time, :os, :platform, :platform_family, :cpu_usage, :memory_usage, :ppid)`
+	// The upsert updates the columns that can change over the lifetime of a process, it also makes RETURNING yield the ID of existing identities
+	identityQuery := `INSERT INTO process_identities (pid, created_time, name, cmdline_hash, cmdline, ppid, exe, cwd, username, os, platform, platform_family, status, cgroup, container_id, systemd_unit, pod_uid)
+	VALUES (:pid, :created_time, :name, :cmdline_hash, :cmdline, :ppid, :exe, :cwd, :username, :os, :platform, :platform_family, :status, :cgroup, :container_id, :systemd_unit, :pod_uid)
+	ON CONFLICT (pid, created_time, name, cmdline_hash) DO UPDATE SET ppid = excluded.ppid, cwd = excluded.cwd, status = excluded.status
+	RETURNING id`
+	sampleQuery := `INSERT INTO process_samples (identity_id, stored_time, cpu_usage, interval_cpu_usage, memory_usage, rss_bytes, vms_bytes, threads, open_fds, read_bytes, write_bytes, voluntary_ctx_switches, involuntary_ctx_switches, command_id)
+	VALUES (:identity_id, :stored_time, :cpu_usage, :interval_cpu_usage, :memory_usage, :rss_bytes, :vms_bytes, :threads, :open_fds, :read_bytes, :write_bytes, :voluntary_ctx_switches, :involuntary_ctx_switches, :command_id)`
 
 	// Begin a transaction
 	tx, err := database.DB.Beginx()
@@ -147,21 +306,33 @@ func InsertProcesses(processes []Process) error {
 		return err
 	}
 
-	// Prepare the statement for execution, within the transaction
-	stmt, err := tx.PrepareNamed(query)
+	// Prepare the statements for execution, within the transaction
+	identityStmt, err := tx.PrepareNamed(identityQuery)
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
-	defer stmt.Close() // Ensure the statement is closed after execution
+	defer identityStmt.Close()
+
+	sampleStmt, err := tx.PrepareNamed(sampleQuery)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer sampleStmt.Close() // Ensure the statements are closed after execution
 
 	for _, process := range processes {
-		// Execute the query for each process
-		_, err := stmt.Exec(process)
-		if err != nil {
+		identity := processIdentity{Process: process, CmdlineHash: util.HashCmdline(process.Cmdline)}
+		if err := identityStmt.Get(&process.IdentityID, identity); err != nil {
 			// In case of an error, roll back the transaction
 			tx.Rollback()
 			return err
 		}
+
+		if _, err := sampleStmt.Exec(process); err != nil {
+			tx.Rollback()
+			return err
+		}
 	}
 
 	// Commit the transaction after all inserts
@@ -170,17 +341,35 @@ func InsertProcesses(processes []Process) error {
 
 func MapProcessToProto(process Process) *gen.Process {
 	return &gen.Process{
-		Id:             process.Id,
-		Pid:            process.PID,
-		Ppid:           process.PPID,
-		Name:           process.Name,
-		Status:         process.Status,
-		CreatedTime:    process.CreatedTime,
-		StoredTime:     process.StoredTime,
-		Os:             process.OS,
-		Platform:       process.Platform,
-		PlatformFamily: process.PlatformFamily,
-		CpuUsage:       process.CPUUsage,
-		MemoryUsage:    process.MemoryUsage,
+		Id:                     process.Id,
+		Pid:                    process.PID,
+		Ppid:                   process.PPID,
+		Name:                   process.Name,
+		Status:                 process.Status,
+		CreatedTime:            process.CreatedTime,
+		StoredTime:             process.StoredTime,
+		Os:                     process.OS,
+		Platform:               process.Platform,
+		PlatformFamily:         process.PlatformFamily,
+		CpuUsage:               process.CPUUsage,
+		IntervalCpuUsage:       process.IntervalCPUUsage,
+		RssBytes:               process.RSSBytes,
+		VmsBytes:               process.VMSBytes,
+		Threads:                process.Threads,
+		OpenFds:                process.OpenFDs,
+		ReadBytes:              process.ReadBytes,
+		WriteBytes:             process.WriteBytes,
+		VoluntaryCtxSwitches:   process.VoluntaryCtxSwitches,
+		InvoluntaryCtxSwitches: process.InvoluntaryCtxSwitches,
+		Cmdline:                process.Cmdline,
+		Exe:                    process.Exe,
+		Cwd:                    process.Cwd,
+		Username:               process.Username,
+		Cgroup:                 process.Cgroup,
+		ContainerId:            process.ContainerID,
+		SystemdUnit:            process.SystemdUnit,
+		PodUid:                 process.PodUID,
+		MemoryUsage:            process.MemoryUsage,
+		CommandId:              process.CommandID,
 	}
 }
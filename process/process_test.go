@@ -1,18 +1,41 @@
 package process
 
 import (
+	"strings"
 	"testing"
+	"unicode/utf8"
 
+	"github.com/devzero-inc/oda/config"
+	"github.com/devzero-inc/oda/database"
+
+	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 )
 
+func setupTestDatabase(t *testing.T) {
+	t.Helper()
+
+	config.SetupSysConfig()
+
+	db, err := sqlx.Connect("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to connect to the database: %s", err)
+	}
+	// Every connection to an in-memory database gets its own database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	database.DB = db
+	database.RunMigrations()
+}
+
 func TestFactory_Create(t *testing.T) {
 	// Create a no-op logger for testing
 	logger := zerolog.Nop()
 
 	// Initialize the factory with the test logger
-	factory := NewFactory(logger)
+	factory := NewFactory(logger, "")
 
 	// Define test cases
 	tests := []struct {
@@ -22,6 +45,7 @@ func TestFactory_Create(t *testing.T) {
 	}{
 		{"Create PsutilType", PsutilType, false},
 		{"Create PsType", PsType, false},
+		{"Create ProcfsType", ProcfsType, false},
 		{"Create Unsupported", "Unsupported", true},
 	}
 
@@ -43,8 +67,102 @@ func TestFactory_Create(t *testing.T) {
 				case PsType:
 					_, ok := sp.(*Ps)
 					assert.True(t, ok, "Expected PsType instance")
+				case ProcfsType:
+					_, ok := sp.(*Procfs)
+					assert.True(t, ok, "Expected ProcfsType instance")
 				}
 			}
 		})
 	}
 }
+
+func TestFactoryCreateProcfsFallsBackToPs(t *testing.T) {
+	factory := NewFactory(zerolog.Nop(), "")
+	factory.goos = "darwin"
+
+	sp, err := factory.Create(ProcfsType)
+	assert.NoError(t, err)
+	_, ok := sp.(*Ps)
+	assert.True(t, ok, "Procfs should fall back to ps without a proc filesystem")
+}
+
+func TestContainerUsage(t *testing.T) {
+	setupTestDatabase(t)
+
+	assert.NoError(t, InsertProcesses([]Process{
+		{PID: 10, Name: "make", StoredTime: 1_000, IntervalCPUUsage: 10, MemoryUsage: 1, CommandID: "cmd"},
+		{PID: 20, Name: "node", StoredTime: 1_000, IntervalCPUUsage: 50, MemoryUsage: 4, CommandID: "cmd", ContainerID: "abc", PodUID: "pod"},
+		{PID: 21, Name: "node", StoredTime: 1_000, IntervalCPUUsage: 30, MemoryUsage: 2, CommandID: "cmd", ContainerID: "abc", PodUID: "pod"},
+		{PID: 20, Name: "node", StoredTime: 2_000, IntervalCPUUsage: 20, MemoryUsage: 5, CommandID: "cmd", ContainerID: "abc", PodUID: "pod"},
+		{PID: 30, Name: "java", StoredTime: 1_000, IntervalCPUUsage: 100, MemoryUsage: 9, ContainerID: "abc"},
+	}))
+
+	usage, err := GetContainerUsageForCommand("cmd")
+	assert.NoError(t, err)
+	if assert.Len(t, usage, 2) {
+		assert.Equal(t, "abc", usage[0].ContainerID)
+		assert.Equal(t, "pod", usage[0].PodUID)
+		assert.Equal(t, int64(2), usage[0].Processes)
+		assert.Equal(t, 80.0, usage[0].PeakCPUUsage)
+		assert.Equal(t, 50.0, usage[0].AvgCPUUsage)
+		assert.Equal(t, 6.0, usage[0].PeakMemoryUsage)
+
+		assert.Equal(t, "", usage[1].ContainerID)
+		assert.Equal(t, int64(1), usage[1].Processes)
+	}
+
+	// Processes not spawned by a command are included in the usage of a period
+	usage, err = GetContainerUsageForPeriod(0, 1_500)
+	assert.NoError(t, err)
+	if assert.Len(t, usage, 2) {
+		assert.Equal(t, "abc", usage[0].ContainerID)
+		assert.Equal(t, int64(3), usage[0].Processes)
+		assert.Equal(t, 180.0, usage[0].PeakCPUUsage)
+	}
+}
+
+func TestTopProcessesAndMetricsSeparatesReusedPIDs(t *testing.T) {
+	setupTestDatabase(t)
+
+	assert.NoError(t, InsertProcesses([]Process{
+		{PID: 10, Name: "make", CreatedTime: 100, StoredTime: 1_000, IntervalCPUUsage: 10, CommandID: "cmd"},
+		{PID: 10, Name: "make", CreatedTime: 100, StoredTime: 2_000, IntervalCPUUsage: 20, CommandID: "cmd"},
+	}))
+	// The PID of make is reused by another process, and node changes its command line
+	assert.NoError(t, InsertProcesses([]Process{
+		{PID: 10, Name: "go", CreatedTime: 2_500, StoredTime: 3_000, IntervalCPUUsage: 30, CommandID: "cmd"},
+		{PID: 11, Name: "node", CreatedTime: 100, StoredTime: 3_000, Cmdline: "node server.js", CommandID: "cmd"},
+		{PID: 11, Name: "node", CreatedTime: 100, StoredTime: 4_000, Cmdline: "node: worker", CommandID: "cmd"},
+	}))
+
+	var identities int
+	assert.NoError(t, database.DB.Get(&identities, "SELECT COUNT(*) FROM process_identities"))
+	assert.Equal(t, 4, identities, "Samples of the same process should share the identity")
+
+	metrics, err := GetTopProcessesAndMetricsForCommand("cmd")
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 4)
+
+	var names []string
+	for _, series := range metrics {
+		if series[0].PID == 10 {
+			names = append(names, series[0].Name)
+			for _, sample := range series {
+				assert.Equal(t, series[0].Name, sample.Name, "Series should not mix processes with the same PID")
+			}
+		}
+	}
+	assert.ElementsMatch(t, []string{"make", "go"}, names)
+
+	processes, err := GetProcessesForCommand("cmd")
+	assert.NoError(t, err)
+	assert.Len(t, processes, 4)
+}
+
+func TestTruncateCmdline(t *testing.T) {
+	assert.Equal(t, "ls -la", TruncateCmdline("ls -la"))
+
+	truncated := TruncateCmdline(strings.Repeat("a", maxCmdlineLength-1) + "é")
+	assert.Len(t, truncated, maxCmdlineLength-1, "Multi-byte character crossing the limit should be dropped")
+	assert.True(t, utf8.ValidString(truncated))
+}
@@ -0,0 +1,352 @@
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultProcRoot is the mount point of the proc filesystem
+	DefaultProcRoot = "/proc"
+	// clockTicks is the number of clock ticks per second used for the times in /proc, USER_HZ is 100 on all supported architectures
+	clockTicks = 100
+	// commLength is the maximum length of the process name in /proc/[pid]/stat, longer names are truncated
+	commLength = 15
+)
+
+// Procfs is the type for the process collector reading the proc filesystem directly, available on Linux only
+type Procfs struct {
+	logger zerolog.Logger
+	// root is the mount point of the proc filesystem, it can point to a fixture tree in tests
+	root      string
+	cpu       *cpuTracker
+	usernames *usernames
+}
+
+// NewProcfs creates a new Procfs instance reading the proc filesystem mounted at root, /proc if empty
+func NewProcfs(logger zerolog.Logger, root string) *Procfs {
+	if root == "" {
+		root = DefaultProcRoot
+	}
+
+	return &Procfs{
+		logger:    logger,
+		root:      root,
+		cpu:       newCPUTracker(),
+		usernames: newUsernames(),
+	}
+}
+
+// procSystem is the system wide information needed to compute the process usage
+type procSystem struct {
+	// bootTime is the boot time in seconds since epoch
+	bootTime int64
+	// uptime is the time since boot in seconds
+	uptime float64
+	// memTotal is the total memory in kB
+	memTotal int64
+}
+
+// procStat is the information parsed from /proc/[pid]/stat
+type procStat struct {
+	pid   int64
+	comm  string
+	state string
+	ppid  int64
+	// utime and stime are the user and system CPU times in clock ticks
+	utime int64
+	stime int64
+	// startTime is the time the process started after boot in clock ticks
+	startTime int64
+}
+
+// Collect collects the process information from the proc filesystem
+func (p *Procfs) Collect() ([]Process, error) {
+	p.logger.Debug().Msg("Collecting process")
+
+	system, err := p.readSystem()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(p.root)
+	if err != nil {
+		return nil, err
+	}
+
+	sampledAt := time.Now()
+	defer p.cpu.finishSample()
+
+	var processInfo []Process
+	for _, entry := range entries {
+		pid, err := strconv.ParseInt(entry.Name(), 10, 64)
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+
+		process, err := p.readProcess(pid, system, sampledAt)
+		if err != nil {
+			// Processes can exit while they are being read, so they are skipped without failing the collection
+			p.logger.Debug().Err(err).Msgf("Skipping process %d", pid)
+			continue
+		}
+		process.StoredTime = sampledAt.UnixMilli()
+
+		processInfo = append(processInfo, process)
+	}
+
+	return processInfo, nil
+}
+
+// readProcess reads a single process from its stat, status, cmdline, io and cgroup files, its fd directory and its exe and cwd links
+func (p *Procfs) readProcess(pid int64, system procSystem, sampledAt time.Time) (Process, error) {
+	dir := filepath.Join(p.root, strconv.FormatInt(pid, 10))
+
+	content, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return Process{}, err
+	}
+	stat, err := parseProcStat(content)
+	if err != nil {
+		return Process{}, err
+	}
+
+	content, err = os.ReadFile(filepath.Join(dir, "status"))
+	if err != nil {
+		return Process{}, err
+	}
+	status := parseProcKeyValues(content)
+
+	// The command line is empty for kernel threads and zombies, and unreadable processes keep the short name
+	cmdline, _ := os.ReadFile(filepath.Join(dir, "cmdline"))
+
+	startSeconds := float64(stat.startTime) / clockTicks
+	createdTime := stat.createdTime(system.bootTime)
+	cpuSeconds := stat.cpuSeconds()
+
+	var cpuUsage float64
+	if elapsed := system.uptime - startSeconds; elapsed > 0 {
+		cpuUsage = cpuSeconds / elapsed * 100
+	}
+
+	rss := parseKilobytes(status["VmRSS"])
+	var memUsage float64
+	if system.memTotal > 0 {
+		memUsage = float64(rss) / float64(system.memTotal) * 100
+	}
+
+	threads, _ := strconv.ParseInt(status["Threads"], 10, 64)
+	voluntary, _ := strconv.ParseInt(status["voluntary_ctxt_switches"], 10, 64)
+	involuntary, _ := strconv.ParseInt(status["nonvoluntary_ctxt_switches"], 10, 64)
+
+	// The io file and fd directory of processes of other users are readable by root only, their metrics stay 0
+	var readBytes, writeBytes int64
+	if content, err := os.ReadFile(filepath.Join(dir, "io")); err == nil {
+		io := parseProcKeyValues(content)
+		readBytes, _ = strconv.ParseInt(io["read_bytes"], 10, 64)
+		writeBytes, _ = strconv.ParseInt(io["write_bytes"], 10, 64)
+	}
+
+	var openFDs int64
+	if fds, err := os.ReadDir(filepath.Join(dir, "fd")); err == nil {
+		openFDs = int64(len(fds))
+	}
+	exe, _ := os.Readlink(filepath.Join(dir, "exe"))
+	cwd, _ := os.Readlink(filepath.Join(dir, "cwd"))
+
+	// The first of the real, effective, saved and filesystem user IDs is the owner
+	uid, _, _ := strings.Cut(status["Uid"], "\t")
+
+	process := Process{
+		PID:                    stat.pid,
+		PPID:                   stat.ppid,
+		Name:                   processName(stat.comm, cmdline),
+		Status:                 stat.state,
+		CPUUsage:               cpuUsage,
+		IntervalCPUUsage:       p.cpu.intervalUsage(stat.pid, createdTime, cpuSeconds, cpuUsage, sampledAt),
+		MemoryUsage:            memUsage,
+		RSSBytes:               rss * 1024,
+		VMSBytes:               parseKilobytes(status["VmSize"]) * 1024,
+		Threads:                threads,
+		OpenFDs:                openFDs,
+		ReadBytes:              readBytes,
+		WriteBytes:             writeBytes,
+		VoluntaryCtxSwitches:   voluntary,
+		InvoluntaryCtxSwitches: involuntary,
+		Cmdline:                joinCmdline(cmdline),
+		Exe:                    exe,
+		Cwd:                    cwd,
+		Username:               p.usernames.lookup(uid),
+		CreatedTime:            createdTime,
+		OS:                     runtime.GOOS,
+		Platform:               runtime.GOOS,
+	}
+	process.setCgroup(readCgroup(p.root, pid))
+
+	return process, nil
+}
+
+// readSystem reads the boot time, uptime and total memory of the system
+func (p *Procfs) readSystem() (procSystem, error) {
+	var system procSystem
+
+	bootTime, err := readBootTime(p.root)
+	if err != nil {
+		return system, err
+	}
+	system.bootTime = bootTime
+
+	content, err := os.ReadFile(filepath.Join(p.root, "uptime"))
+	if err != nil {
+		return system, err
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return system, fmt.Errorf("invalid uptime: %q", content)
+	}
+	system.uptime, err = strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return system, fmt.Errorf("invalid uptime: %w", err)
+	}
+
+	content, err = os.ReadFile(filepath.Join(p.root, "meminfo"))
+	if err != nil {
+		return system, err
+	}
+	system.memTotal = parseKilobytes(parseProcKeyValues(content)["MemTotal"])
+
+	return system, nil
+}
+
+// readBootTime reads the boot time of the system in seconds since the epoch from /proc/stat
+func readBootTime(root string) (int64, error) {
+	content, err := os.ReadFile(filepath.Join(root, "stat"))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if value, ok := strings.CutPrefix(line, "btime "); ok {
+			bootTime, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid boot time: %w", err)
+			}
+			return bootTime, nil
+		}
+	}
+
+	return 0, fmt.Errorf("boot time not found")
+}
+
+// readProcStat reads and parses /proc/[pid]/stat
+func readProcStat(root string, pid int64) (procStat, error) {
+	content, err := os.ReadFile(filepath.Join(root, strconv.FormatInt(pid, 10), "stat"))
+	if err != nil {
+		return procStat{}, err
+	}
+
+	return parseProcStat(content)
+}
+
+// cpuSeconds returns the total user and system CPU time of the process in seconds
+func (s procStat) cpuSeconds() float64 {
+	return float64(s.utime+s.stime) / clockTicks
+}
+
+// createdTime returns the time the process started in milliseconds since the epoch, bootTime is in seconds
+func (s procStat) createdTime(bootTime int64) int64 {
+	return bootTime*1000 + int64(float64(s.startTime)/clockTicks*1000)
+}
+
+// parseProcStat parses the content of /proc/[pid]/stat. The name is enclosed in parentheses and
+// can contain spaces and parentheses itself, so the fields are split after the last closing one.
+func parseProcStat(content []byte) (procStat, error) {
+	var stat procStat
+
+	line := string(bytes.TrimSpace(content))
+	open := strings.IndexByte(line, '(')
+	closing := strings.LastIndexByte(line, ')')
+	if open < 0 || closing < open {
+		return stat, fmt.Errorf("invalid stat: %q", line)
+	}
+
+	// The fields after the name start with the state, the third field of the file
+	fields := strings.Fields(line[closing+1:])
+	if len(fields) < 20 {
+		return stat, fmt.Errorf("invalid stat, expected at least 22 fields: %q", line)
+	}
+
+	var err error
+	if stat.pid, err = strconv.ParseInt(strings.TrimSpace(line[:open]), 10, 64); err != nil {
+		return stat, fmt.Errorf("invalid pid: %w", err)
+	}
+	stat.comm = line[open+1 : closing]
+	stat.state = fields[0]
+	if stat.ppid, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+		return stat, fmt.Errorf("invalid ppid: %w", err)
+	}
+	if stat.utime, err = strconv.ParseInt(fields[11], 10, 64); err != nil {
+		return stat, fmt.Errorf("invalid utime: %w", err)
+	}
+	if stat.stime, err = strconv.ParseInt(fields[12], 10, 64); err != nil {
+		return stat, fmt.Errorf("invalid stime: %w", err)
+	}
+	if stat.startTime, err = strconv.ParseInt(fields[19], 10, 64); err != nil {
+		return stat, fmt.Errorf("invalid start time: %w", err)
+	}
+
+	return stat, nil
+}
+
+// parseProcKeyValues parses files with "Key: value" lines, like /proc/[pid]/status and /proc/meminfo
+func parseProcKeyValues(content []byte) map[string]string {
+	values := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		values[key] = strings.TrimSpace(value)
+	}
+
+	return values
+}
+
+// parseKilobytes parses a "1234 kB" value, returning 0 if it is missing or invalid
+func parseKilobytes(value string) int64 {
+	kilobytes, _ := strconv.ParseInt(strings.TrimSuffix(value, " kB"), 10, 64)
+
+	return kilobytes
+}
+
+// processName returns the name of the process. The name in stat is truncated to 15 characters,
+// so a truncated name is completed from the executable in the command line when it matches.
+func processName(comm string, cmdline []byte) string {
+	if len(comm) < commLength {
+		return comm
+	}
+
+	executable, _, _ := bytes.Cut(cmdline, []byte{0})
+	if name := path.Base(string(executable)); strings.HasPrefix(name, comm) {
+		return name
+	}
+
+	return comm
+}
+
+// joinCmdline joins the NUL separated arguments of /proc/[pid]/cmdline with spaces
+func joinCmdline(cmdline []byte) string {
+	return strings.TrimSpace(string(bytes.ReplaceAll(bytes.TrimRight(cmdline, "\x00"), []byte{0}, []byte{' '})))
+}
@@ -0,0 +1,144 @@
+package process
+
+import (
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcfsCollectFixture(t *testing.T) {
+	procfs := NewProcfs(zerolog.Nop(), "testdata/proc")
+
+	processes, err := procfs.Collect()
+	assert.NoError(t, err, "Collect method should not return an error")
+
+	// The process without a stat file exited while it was being read and is skipped
+	sort.Slice(processes, func(i, j int) bool { return processes[i].PID < processes[j].PID })
+	if !assert.Len(t, processes, 4) {
+		return
+	}
+
+	systemd := processes[0]
+	assert.Equal(t, int64(1), systemd.PID)
+	assert.Equal(t, int64(0), systemd.PPID)
+	assert.Equal(t, "systemd", systemd.Name)
+	assert.Equal(t, "S", systemd.Status)
+	assert.InDelta(t, 0.2, systemd.CPUUsage, 0.0001)
+	assert.InDelta(t, 1.0, systemd.MemoryUsage, 0.0001)
+	assert.Equal(t, int64(1700000000000), systemd.CreatedTime)
+	assert.NotZero(t, systemd.StoredTime)
+	assert.Equal(t, "/sbin/init splash", systemd.Cmdline)
+	assert.Equal(t, "root", systemd.Username)
+	assert.Equal(t, "/init.scope", systemd.Cgroup)
+	assert.Equal(t, "init.scope", systemd.SystemdUnit)
+	assert.Empty(t, systemd.ContainerID)
+
+	kthreadd := processes[1]
+	assert.Equal(t, "kthreadd", kthreadd.Name)
+	assert.Zero(t, kthreadd.CPUUsage)
+	assert.Zero(t, kthreadd.MemoryUsage)
+	assert.Zero(t, kthreadd.RSSBytes)
+	assert.Zero(t, kthreadd.OpenFDs, "Unreadable fd directory should not fail the collection")
+	assert.Empty(t, kthreadd.Cmdline)
+	assert.Empty(t, kthreadd.Exe)
+
+	tmux := processes[2]
+	assert.Equal(t, "tmux: server (1)", tmux.Name)
+	assert.Equal(t, int64(1), tmux.PPID)
+	assert.InDelta(t, 1.0, tmux.CPUUsage, 0.0001)
+	assert.InDelta(t, 5.0, tmux.MemoryUsage, 0.0001)
+	assert.Equal(t, int64(1700000500000), tmux.CreatedTime)
+
+	server := processes[3]
+	assert.Equal(t, "language_server_linux_x64", server.Name, "Truncated name should be completed from the command line")
+	assert.Equal(t, "R", server.Status)
+	assert.InDelta(t, 50.0, server.CPUUsage, 0.0001)
+	assert.InDelta(t, 10.0, server.MemoryUsage, 0.0001)
+
+	assert.Equal(t, int64(100000*1024), server.RSSBytes)
+	assert.Equal(t, int64(400000*1024), server.VMSBytes)
+	assert.Equal(t, int64(8), server.Threads)
+	assert.Equal(t, int64(4), server.OpenFDs)
+	assert.Equal(t, int64(65536), server.ReadBytes)
+	assert.Equal(t, int64(8192), server.WriteBytes)
+	assert.Equal(t, int64(1500), server.VoluntaryCtxSwitches)
+	assert.Equal(t, int64(250), server.InvoluntaryCtxSwitches)
+	assert.Equal(t, "/usr/lib/language_server_linux_x64 --stdio", server.Cmdline)
+	assert.Equal(t, "/usr/lib/language_server_linux_x64", server.Exe)
+	assert.Equal(t, "/home/dev/project", server.Cwd)
+	assert.NotEmpty(t, server.Username)
+	assert.Equal(t, testContainerID, server.ContainerID)
+	assert.Equal(t, "0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0", server.PodUID)
+	assert.Equal(t, "cri-containerd-"+testContainerID+".scope", server.SystemdUnit)
+
+	// Without a previous sample the interval usage falls back to the lifetime usage
+	assert.Equal(t, server.CPUUsage, server.IntervalCPUUsage)
+
+	// The fixture does not change, so no CPU time was used since the previous sample
+	processes, err = procfs.Collect()
+	assert.NoError(t, err)
+	for _, process := range processes {
+		assert.Zero(t, process.IntervalCPUUsage, process.Name)
+	}
+}
+
+func TestProcfsCollectMissingRoot(t *testing.T) {
+	procfs := NewProcfs(zerolog.Nop(), "testdata/missing")
+
+	_, err := procfs.Collect()
+	assert.Error(t, err)
+}
+
+func TestParseProcStatInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"42 tmux S 1",
+		"42 (tmux) S 1 42",
+		"x (tmux) S 1 42 42 0 -1 4194560 50 0 0 0 300 200 0 0 20 0 1 0 50000",
+	}
+
+	for _, content := range tests {
+		_, err := parseProcStat([]byte(content))
+		assert.Error(t, err, content)
+	}
+}
+
+func TestProcfsCollectWithRealOutput(t *testing.T) {
+	if _, err := os.Stat(DefaultProcRoot + "/stat"); err != nil {
+		t.Skip("proc filesystem is not available")
+	}
+
+	procfs := NewProcfs(zerolog.Nop(), "")
+
+	processes, err := procfs.Collect()
+
+	assert.NoError(t, err, "Collect method should not return an error")
+	assert.NotEmpty(t, processes, "Collect method should return list of processes")
+}
+
+func BenchmarkProcfsCollect(b *testing.B) {
+	if _, err := os.Stat(DefaultProcRoot + "/stat"); err != nil {
+		b.Skip("proc filesystem is not available")
+	}
+
+	procfs := NewProcfs(zerolog.Nop(), "")
+
+	for i := 0; i < b.N; i++ {
+		if _, err := procfs.Collect(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProcfsCollectFixture(b *testing.B) {
+	procfs := NewProcfs(zerolog.Nop(), "testdata/proc")
+
+	for i := 0; i < b.N; i++ {
+		if _, err := procfs.Collect(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
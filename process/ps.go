@@ -3,80 +3,346 @@ package process
 import (
 	"bufio"
 	"bytes"
+	"fmt"
+	"os"
 	"os/exec"
 	"path"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
 )
 
+// startTimeTolerance is the difference up to which start times derived from the elapsed time are considered the same,
+// the elapsed time has a resolution of one second and is read at a slightly different time than the sample is taken
+const startTimeTolerance = 2 * time.Second
+
+// commandRunner runs a command and returns its standard output, the standard error is part of the returned error
+type commandRunner func(name string, args ...string) ([]byte, error)
+
+// runCommand runs the command with the C locale, so numbers and times are formatted the same on every system
+func runCommand(name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// psLine is a process parsed from a line of the ps output
+type psLine struct {
+	pid  int64
+	ppid int64
+	// cpuUsage and memoryUsage are the percentages reported by ps, hasUsage is false if ps does not report them
+	cpuUsage    float64
+	memoryUsage float64
+	hasUsage    bool
+	// cpuTime and elapsed are the cumulative CPU time and the time since the process started in [[dd-]hh:]mm:ss or seconds
+	cpuTime string
+	elapsed string
+	// rss and vsz are the memory sizes in KiB
+	rss int64
+	vsz int64
+	// uid is the owner's user ID, user is the owner's name if ps reports it instead
+	uid  string
+	user string
+	name string
+}
+
+// psFormat is the columns requested from a ps implementation and how its output is parsed
+type psFormat struct {
+	// args list all processes, cmdlineArgs list the PID and full command line of all processes
+	args        []string
+	cmdlineArgs []string
+	// parse parses the fields of a line, ok is false for lines that are not a process like the header
+	parse func(fields []string) (line psLine, ok bool)
+}
+
+// standardFormat is the format of procps on Linux and of the BSD ps on macOS, which only supports the elapsed time
+// as [[dd-]hh:]mm:ss, the remaining metrics are collected by psutil and procfs only
+func standardFormat(goos string) psFormat {
+	elapsed := "etimes"
+	if goos != "linux" {
+		elapsed = "etime"
+	}
+
+	return psFormat{
+		args:        []string{"axo", "pid,ppid,pcpu,pmem,time,rss,vsz,uid," + elapsed + ",comm"},
+		cmdlineArgs: []string{"-ww", "axo", "pid=,args="},
+		parse: func(fields []string) (psLine, bool) {
+			if len(fields) < 10 {
+				return psLine{}, false
+			}
+
+			line := psLine{cpuTime: fields[4], elapsed: fields[8], uid: fields[7], hasUsage: true}
+			if !parseInts(fields, []int{0, 1, 5, 6}, &line.pid, &line.ppid, &line.rss, &line.vsz) {
+				return psLine{}, false
+			}
+
+			var err error
+			if line.cpuUsage, err = strconv.ParseFloat(fields[2], 64); err != nil {
+				return psLine{}, false
+			}
+			if line.memoryUsage, err = strconv.ParseFloat(fields[3], 64); err != nil {
+				return psLine{}, false
+			}
+
+			// The command name might contain spaces, so the remaining fields are joined
+			line.name = strings.Join(fields[9:], " ")
+
+			return line, true
+		},
+	}
+}
+
+// busyBoxFormat is the format of the BusyBox ps used by Alpine images, it does not support the ax options
+// nor the usage percentages, so the CPU usage is computed from the CPU time and the memory usage is left 0
+var busyBoxFormat = psFormat{
+	args:        []string{"-o", "pid,ppid,time,etime,vsz,user,comm"},
+	cmdlineArgs: []string{"-w", "-o", "pid,args"},
+	parse: func(fields []string) (psLine, bool) {
+		if len(fields) < 7 {
+			return psLine{}, false
+		}
+
+		line := psLine{cpuTime: fields[2], elapsed: fields[3], user: fields[5]}
+		if !parseInts(fields, []int{0, 1}, &line.pid, &line.ppid) {
+			return psLine{}, false
+		}
+		// BusyBox shows large sizes with a suffix like 120m, they are left 0 as the sizes are approximate anyway
+		line.vsz, _ = strconv.ParseInt(fields[4], 10, 64)
+
+		line.name = strings.Join(fields[6:], " ")
+
+		return line, true
+	},
+}
+
+// parseInts parses the fields at the indexes into the values, it reports whether all of them are integers
+func parseInts(fields []string, indexes []int, values ...*int64) bool {
+	for i, index := range indexes {
+		value, err := strconv.ParseInt(fields[index], 10, 64)
+		if err != nil {
+			return false
+		}
+		*values[i] = value
+	}
+
+	return true
+}
+
+// startTimes keeps the start times derived from the elapsed times stable between samples,
+// as the processes are identified by their PID and start time
+type startTimes struct {
+	mutex    sync.Mutex
+	previous map[int64]int64
+	current  map[int64]int64
+}
+
+// newStartTimes creates a new startTimes instance
+func newStartTimes() *startTimes {
+	return &startTimes{
+		previous: make(map[int64]int64),
+		current:  make(map[int64]int64),
+	}
+}
+
+// stable returns the start time of the process in the previous sample if it is within the tolerance of the estimate
+func (s *startTimes) stable(pid int64, estimate int64) int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	startTime := estimate
+	if previous, ok := s.previous[pid]; ok && (time.Duration(estimate-previous)*time.Millisecond).Abs() <= startTimeTolerance {
+		startTime = previous
+	}
+	s.current[pid] = startTime
+
+	return startTime
+}
+
+// finishSample makes the start times of the current sample the previous ones
+func (s *startTimes) finishSample() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.previous = s.current
+	s.current = make(map[int64]int64, len(s.previous))
+}
+
 // Ps is the type for the ps process collector
 type Ps struct {
-	logger zerolog.Logger
+	logger     zerolog.Logger
+	cpu        *cpuTracker
+	usernames  *usernames
+	startTimes *startTimes
+	// run runs the ps commands, it is replaced with recorded outputs in tests
+	run  commandRunner
+	goos string
+	// procRoot is the root of the proc filesystem the CPU times and cgroups are read from, empty if there is none
+	procRoot string
+
+	// format is the format of the ps implementation, detected on the first collection
+	formatOnce sync.Once
+	format     psFormat
 }
 
 // NewPs creates a new Ps instance
 func NewPs(logger zerolog.Logger) *Ps {
+	var procRoot string
+	if runtime.GOOS == "linux" {
+		procRoot = DefaultProcRoot
+	}
+
+	return newPs(logger, runCommand, runtime.GOOS, procRoot)
+}
+
+// newPs creates a new Ps instance running the ps commands with the runner and reading the CPU times and cgroups from procRoot
+func newPs(logger zerolog.Logger, run commandRunner, goos string, procRoot string) *Ps {
 	return &Ps{
-		logger: logger,
+		logger:     logger,
+		cpu:        newCPUTracker(),
+		usernames:  newUsernames(),
+		startTimes: newStartTimes(),
+		run:        run,
+		goos:       goos,
+		procRoot:   procRoot,
 	}
 }
 
+// detectFormat detects the ps implementation, BusyBox rejects the version option and shows its banner in the usage
+func (p *Ps) detectFormat() psFormat {
+	p.formatOnce.Do(func() {
+		p.format = standardFormat(p.goos)
+
+		out, err := p.run("ps", "-V")
+		if bytes.Contains(out, []byte("BusyBox")) || (err != nil && strings.Contains(err.Error(), "BusyBox")) {
+			p.logger.Debug().Msg("Detected BusyBox ps")
+			p.format = busyBoxFormat
+		}
+	})
+
+	return p.format
+}
+
 // Collect collects the process information using the ps command
 func (p *Ps) Collect() ([]Process, error) {
 	p.logger.Debug().Msg("Collecting process")
 
-	// Adjust the command to include PPID
-	cmd := exec.Command("ps", "axo", "pid,ppid,pcpu,pmem,lstart,comm")
+	format := p.detectFormat()
 
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	err := cmd.Run()
+	out, err := p.run("ps", format.args...)
 	if err != nil {
 		return nil, err
 	}
 
-	scanner := bufio.NewScanner(&out)
-	scanner.Scan() // Skip the header line
+	sampledAt := time.Now()
+	defer p.cpu.finishSample()
+	defer p.startTimes.finishSample()
+
+	var bootTime int64
+	if p.procRoot != "" {
+		if bootTime, err = readBootTime(p.procRoot); err != nil {
+			p.logger.Err(err).Msg("Error reading boot time")
+		}
+	}
+
+	// The command line can contain spaces as well as the name, so it is read separately
+	cmdlines, err := p.collectCmdlines(format)
+	if err != nil {
+		p.logger.Err(err).Msg("Error collecting command lines")
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
 
 	var processInfo []Process
 
 	for scanner.Scan() {
-		line := scanner.Text()
-		fields := strings.Fields(line)
-
-		// Parse PID, PPID, CPU and MEM usage
-		pid, _ := strconv.ParseInt(fields[0], 10, 64)
-		ppid, _ := strconv.ParseInt(fields[1], 10, 64)
-		cpuUsage, _ := strconv.ParseFloat(fields[2], 64)
-		memUsage, _ := strconv.ParseFloat(fields[3], 64)
-
-		// Parse the start time
-		lstart := strings.Join(fields[4:9], " ")
-		const lstartLayout = "Mon Jan 2 15:04:05 2006"
-		startTime, err := time.Parse(lstartLayout, lstart)
-		if err != nil {
-			p.logger.Err(err).Msg("Error parsing start time")
+		// Lines that are not a process, like the header, are skipped
+		line, ok := format.parse(strings.Fields(scanner.Text()))
+		if !ok {
 			continue
 		}
 
-		// Command name might contain spaces, so we join remaining fields
-		name := strings.Join(fields[9:], " ")
+		// On Linux the start and CPU times are read in clock ticks from /proc, the same way as by the procfs collector
+		var stat procStat
+		hasStat := false
+		if p.procRoot != "" {
+			var err error
+			stat, err = readProcStat(p.procRoot, line.pid)
+			hasStat = err == nil
+		}
+
+		// The start time identifies the process, so the exact one from /proc is preferred. Elsewhere it is estimated
+		// from the elapsed time, which unlike the start date is not formatted by the locale. The estimate depends on
+		// the time ps runs at, so it is rounded to whole seconds to keep it the same after a restart of the collector
+		// and kept stable between samples for the processes it still varies for.
+		var createdTime int64
+		elapsed, hasElapsed := parseCPUTime(line.elapsed)
+		switch {
+		case hasStat && bootTime > 0:
+			createdTime = stat.createdTime(bootTime)
+		case hasElapsed:
+			estimate := sampledAt.Add(-time.Duration(elapsed * float64(time.Second))).Round(time.Second)
+			createdTime = p.startTimes.stable(line.pid, estimate.UnixMilli())
+		default:
+			p.logger.Debug().Int64("pid", line.pid).Str("elapsed", line.elapsed).Msg("Error parsing elapsed time")
+		}
+
+		// procps and BusyBox print the CPU time in whole seconds, so the usage over an interval of a few seconds
+		// would jump between 0 and 100%. The interval usage does not fall back to ps for a process without a stat
+		// so the two sources are never mixed. The BSD ps on macOS prints hundredths of a second, which is fine enough.
+		cpuSeconds, hasCPUTime := parseCPUTime(line.cpuTime)
+		hasIntervalCPUTime := hasCPUTime && p.procRoot == ""
+		if hasStat {
+			cpuSeconds, hasCPUTime, hasIntervalCPUTime = stat.cpuSeconds(), true, true
+		}
+
+		cpuUsage := line.cpuUsage
+		if !line.hasUsage && hasCPUTime && hasElapsed && elapsed > 0 {
+			cpuUsage = cpuSeconds / elapsed * 100
+		}
+
+		intervalCPUUsage := cpuUsage
+		if hasIntervalCPUTime {
+			intervalCPUUsage = p.cpu.intervalUsage(line.pid, createdTime, cpuSeconds, cpuUsage, sampledAt)
+		}
+
+		username := line.user
+		if username == "" {
+			username = p.usernames.lookup(line.uid)
+		}
 
 		// Create the Process instance
 		process := Process{
-			PID:         pid,
-			PPID:        ppid,
-			Name:        path.Base(name),
-			CPUUsage:    cpuUsage,
-			MemoryUsage: memUsage,
-			CreatedTime: startTime.UnixMilli(),
-			StoredTime:  time.Now().UnixMilli(),
-			OS:          runtime.GOOS,
-			Platform:    runtime.GOOS,
+			PID:              line.pid,
+			PPID:             line.ppid,
+			Name:             path.Base(line.name),
+			CPUUsage:         cpuUsage,
+			IntervalCPUUsage: intervalCPUUsage,
+			MemoryUsage:      line.memoryUsage,
+			RSSBytes:         line.rss * 1024,
+			VMSBytes:         line.vsz * 1024,
+			Cmdline:          cmdlines[line.pid],
+			Username:         username,
+			CreatedTime:      createdTime,
+			StoredTime:       sampledAt.UnixMilli(),
+			OS:               p.goos,
+			Platform:         p.goos,
+		}
+
+		// Cgroups exist only on Linux, elsewhere there is no proc filesystem to read them from
+		if p.procRoot != "" {
+			process.setCgroup(readCgroup(p.procRoot, line.pid))
 		}
 
 		// Append to the list of processes
@@ -89,3 +355,27 @@ func (p *Ps) Collect() ([]Process, error) {
 
 	return processInfo, nil
 }
+
+// collectCmdlines collects the command lines of all processes mapped by their PID
+func (p *Ps) collectCmdlines(format psFormat) (map[int64]string, error) {
+	out, err := p.run("ps", format.cmdlineArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdlines := make(map[int64]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	// Command lines are often longer than the default limit of the scanner
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		pidField, args, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		pid, err := strconv.ParseInt(pidField, 10, 64)
+		if err != nil {
+			continue
+		}
+		cmdlines[pid] = strings.TrimSpace(args)
+	}
+
+	return cmdlines, scanner.Err()
+}
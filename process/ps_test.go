@@ -1,12 +1,207 @@
 package process
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 )
 
+// recording is a recorded output of a ps command in testdata/ps
+type recording struct {
+	file string
+	// failed returns the output as the error of a failed command, like the usage printed for an unknown option
+	failed bool
+}
+
+// recordedRunner returns a command runner replaying the recordings keyed by the arguments of the command
+func recordedRunner(t *testing.T, recordings map[string]recording) commandRunner {
+	return func(name string, args ...string) ([]byte, error) {
+		recorded, ok := recordings[strings.Join(args, " ")]
+		if !ok {
+			return nil, errors.New("exit status 1: unexpected command " + name + " " + strings.Join(args, " "))
+		}
+
+		content, err := os.ReadFile(filepath.Join("testdata/ps", recorded.file))
+		if err != nil {
+			t.Fatalf("failed to read recording: %s", err)
+		}
+		if recorded.failed {
+			return nil, errors.New("exit status 1: " + string(content))
+		}
+
+		return content, nil
+	}
+}
+
+func TestPsCollectProcps(t *testing.T) {
+	ps := newPs(zerolog.Nop(), recordedRunner(t, map[string]recording{
+		"-V": {file: "procps_version.txt"},
+		"axo pid,ppid,pcpu,pmem,time,rss,vsz,uid,etimes,comm": {file: "procps.txt"},
+		"-ww axo pid=,args=": {file: "procps_cmdlines.txt"},
+	}), "linux", "")
+
+	processes, err := ps.Collect()
+	assert.NoError(t, err, "Collect method should not return an error")
+	if !assert.Len(t, processes, 4) {
+		return
+	}
+
+	tmux := processes[2]
+	assert.Equal(t, int64(4242), tmux.PID)
+	assert.Equal(t, int64(1), tmux.PPID)
+	assert.Equal(t, "tmux: server", tmux.Name)
+	assert.Equal(t, 1.5, tmux.CPUUsage)
+	assert.Equal(t, 1.5, tmux.IntervalCPUUsage, "First sample should fall back to the lifetime usage")
+	assert.Equal(t, 0.4, tmux.MemoryUsage)
+	assert.Equal(t, int64(36864*1024), tmux.RSSBytes)
+	assert.Equal(t, int64(102400*1024), tmux.VMSBytes)
+	assert.Equal(t, "root", tmux.Username)
+	assert.Equal(t, "tmux new-session -s dev", tmux.Cmdline)
+	assert.Empty(t, tmux.Cgroup, "Cgroup should not be read without a proc filesystem")
+	assert.InDelta(t, time.Now().Add(-6000*time.Second).UnixMilli(), tmux.CreatedTime, 5000)
+	assert.Zero(t, tmux.CreatedTime%1000, "Estimated start time should be rounded to whole seconds")
+
+	server := processes[3]
+	assert.Equal(t, "language_server_linux_x64", server.Name)
+	assert.Equal(t, "/opt/language_server_linux_x64 --stdio --log-level=info", server.Cmdline)
+
+	// The start time derived from the elapsed time should not change between samples, it identifies the process
+	again, err := ps.Collect()
+	assert.NoError(t, err)
+	if assert.Len(t, again, 4) {
+		assert.Equal(t, tmux.CreatedTime, again[2].CreatedTime)
+	}
+}
+
+func TestPsCollectReadsProc(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "4242"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "stat"), []byte("cpu  1 2 3 4\nbtime 1700000000\nprocesses 4242\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeStat := func(utime int) {
+		t.Helper()
+		stat := fmt.Sprintf("4242 (tmux: server) S 1 4242 4242 0 -1 4194560 100 0 0 0 %d 0 0 0 20 0 1 0 12345 20000000 2500\n", utime)
+		if err := os.WriteFile(filepath.Join(root, "4242", "stat"), []byte(stat), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ps := newPs(zerolog.Nop(), recordedRunner(t, map[string]recording{
+		"-V": {file: "procps_version.txt"},
+		"axo pid,ppid,pcpu,pmem,time,rss,vsz,uid,etimes,comm": {file: "procps.txt"},
+		"-ww axo pid=,args=": {file: "procps_cmdlines.txt"},
+	}), "linux", root)
+
+	cgroup := "0::/system.slice/docker-" + testContainerID + ".scope\n"
+	if err := os.WriteFile(filepath.Join(root, "4242", "cgroup"), []byte(cgroup), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	writeStat(9000)
+	_, err := ps.Collect()
+	assert.NoError(t, err)
+
+	// The CPU time printed by ps stays the same, the 5 clock ticks are only visible in /proc
+	writeStat(9005)
+	processes, err := ps.Collect()
+	assert.NoError(t, err)
+	if assert.Len(t, processes, 4) {
+		assert.Positive(t, processes[2].IntervalCPUUsage, "Usage below the resolution of ps should be measured")
+		assert.Equal(t, processes[0].CPUUsage, processes[0].IntervalCPUUsage, "Process without a stat file should keep the lifetime usage")
+		assert.Equal(t, testContainerID, processes[2].ContainerID)
+		assert.Equal(t, int64(1700000000000+123450), processes[2].CreatedTime, "Start time should be exact from the stat file")
+		assert.Zero(t, processes[0].CreatedTime%1000, "Estimated start time should be rounded to whole seconds")
+	}
+}
+
+func TestPsCollectDarwin(t *testing.T) {
+	ps := newPs(zerolog.Nop(), recordedRunner(t, map[string]recording{
+		"axo pid,ppid,pcpu,pmem,time,rss,vsz,uid,etime,comm": {file: "darwin.txt"},
+		"-ww axo pid=,args=": {file: "darwin_cmdlines.txt"},
+	}), "darwin", "")
+
+	processes, err := ps.Collect()
+	assert.NoError(t, err, "Unsupported version option should fall back to the standard format")
+	if !assert.Len(t, processes, 2) {
+		return
+	}
+
+	launchd := processes[0]
+	assert.Equal(t, "launchd", launchd.Name)
+	assert.InDelta(t, time.Now().Add(-(2*24*time.Hour + 3*time.Hour + 4*time.Minute + 5*time.Second)).UnixMilli(), launchd.CreatedTime, 5000)
+
+	chrome := processes[1]
+	assert.Equal(t, "Google Chrome", chrome.Name, "Name with spaces should be joined")
+	assert.Equal(t, 25.0, chrome.CPUUsage)
+	assert.Equal(t, "/Applications/Google Chrome.app/Contents/MacOS/Google Chrome --type=browser", chrome.Cmdline)
+	assert.InDelta(t, time.Now().Add(-84*time.Second).UnixMilli(), chrome.CreatedTime, 5000)
+}
+
+func TestPsCollectBusyBox(t *testing.T) {
+	ps := newPs(zerolog.Nop(), recordedRunner(t, map[string]recording{
+		"-V":                                   {file: "busybox_usage.txt", failed: true},
+		"-o pid,ppid,time,etime,vsz,user,comm": {file: "busybox.txt"},
+		"-w -o pid,args":                       {file: "busybox_cmdlines.txt"},
+	}), "linux", "")
+
+	processes, err := ps.Collect()
+	assert.NoError(t, err, "Collect method should not return an error")
+	if !assert.Len(t, processes, 3) {
+		return
+	}
+
+	shell := processes[0]
+	assert.Equal(t, "sh", shell.Name)
+	assert.Equal(t, "root", shell.Username)
+	assert.Equal(t, int64(1636*1024), shell.VMSBytes)
+	assert.Equal(t, "/bin/sh -c npm start", shell.Cmdline)
+
+	node := processes[1]
+	assert.Equal(t, int64(42), node.PID)
+	assert.Equal(t, "node", node.Username)
+	assert.InDelta(t, 5.0, node.CPUUsage, 0.0001, "CPU usage should be computed from the CPU and elapsed times")
+	assert.Zero(t, node.MemoryUsage)
+	assert.Zero(t, node.VMSBytes, "Size with a suffix should be left 0")
+	assert.Equal(t, "node server.js --port 3000", node.Cmdline)
+	assert.InDelta(t, time.Now().Add(-10*time.Minute).UnixMilli(), node.CreatedTime, 5000)
+}
+
+func TestPsCollectMalformed(t *testing.T) {
+	ps := newPs(zerolog.Nop(), recordedRunner(t, map[string]recording{
+		"-V": {file: "procps_version.txt"},
+		"axo pid,ppid,pcpu,pmem,time,rss,vsz,uid,etimes,comm": {file: "malformed.txt"},
+	}), "linux", "")
+
+	processes, err := ps.Collect()
+	assert.NoError(t, err, "Failed command lines should not fail the collection")
+
+	// Short lines and lines with invalid numbers are skipped, an invalid elapsed time only leaves the start time unknown
+	if assert.Len(t, processes, 2) {
+		assert.Equal(t, int64(1), processes[0].PID)
+		assert.Equal(t, int64(81), processes[1].PID)
+		assert.Equal(t, "zombie", processes[1].Name)
+		assert.Zero(t, processes[1].CreatedTime)
+		assert.Empty(t, processes[1].Cmdline)
+	}
+}
+
+func TestPsCollectCommandError(t *testing.T) {
+	ps := newPs(zerolog.Nop(), recordedRunner(t, map[string]recording{}), "linux", "")
+
+	_, err := ps.Collect()
+	assert.Error(t, err)
+}
+
 func TestPsCollectWithRealOutput(t *testing.T) {
 	// Create a no-op logger for testing
 	logger := zerolog.Nop()
@@ -17,4 +212,25 @@ func TestPsCollectWithRealOutput(t *testing.T) {
 
 	assert.NoError(t, err, "Collect method should not return an error")
 	assert.NotEmpty(t, processes, "Collect method should return list of processes")
+
+	var rss int64
+	var cmdlines int
+	for _, process := range processes {
+		rss += process.RSSBytes
+		if process.Cmdline != "" && process.Username != "" {
+			cmdlines++
+		}
+	}
+	assert.Positive(t, rss, "Collect method should return the memory sizes")
+	assert.Positive(t, cmdlines, "Collect method should return the command lines and owners")
+}
+
+func BenchmarkPsCollect(b *testing.B) {
+	ps := NewPs(zerolog.Nop())
+
+	for i := 0; i < b.N; i++ {
+		if _, err := ps.Collect(); err != nil {
+			b.Fatal(err)
+		}
+	}
 }
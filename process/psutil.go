@@ -1,6 +1,7 @@
 package process
 
 import (
+	"runtime"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -11,12 +12,14 @@ import (
 // Psutil is the type for the psutil process collector
 type Psutil struct {
 	logger zerolog.Logger
+	cpu    *cpuTracker
 }
 
 // NewPsutil creates a new Psutil instance
 func NewPsutil(logger zerolog.Logger) *Psutil {
 	return &Psutil{
 		logger: logger,
+		cpu:    newCPUTracker(),
 	}
 }
 
@@ -33,6 +36,9 @@ func (p *Psutil) Collect() ([]Process, error) {
 		return nil, err
 	}
 
+	sampledAt := time.Now()
+	defer p.cpu.finishSample()
+
 	var processInfo []Process
 	for _, proc := range processes {
 		createTime, err := proc.CreateTime()
@@ -47,12 +53,19 @@ func (p *Psutil) Collect() ([]Process, error) {
 			continue
 		}
 
-		cpuPercent, err := proc.CPUPercent()
+		times, err := proc.Times()
 		if err != nil {
-			p.logger.Err(err).Msg("Error retrieving CPU percent")
+			p.logger.Err(err).Msg("Error retrieving CPU times")
 			continue
 		}
 
+		// The lifetime usage is computed the same way as by CPUPercent, the times are needed for the interval usage anyway
+		cpuSeconds := times.User + times.System
+		var cpuPercent float64
+		if elapsed := sampledAt.Sub(time.UnixMilli(createTime)).Seconds(); elapsed > 0 {
+			cpuPercent = cpuSeconds / elapsed * 100
+		}
+
 		memorypercent, err := proc.MemoryPercent()
 		if err != nil {
 			p.logger.Err(err).Msg("Error retrieving memory percent")
@@ -70,19 +83,61 @@ func (p *Psutil) Collect() ([]Process, error) {
 			p.logger.Err(err).Msg("Error retrieving parent PID")
 		}
 
-		processInfo = append(processInfo, Process{
-			PID:            int64(proc.Pid),
-			PPID:           int64(ppid),
-			Name:           name,
-			Status:         status,
-			CreatedTime:    createTime,
-			StoredTime:     time.Now().UnixMilli(),
-			OS:             hostInfo.OS,
-			Platform:       hostInfo.Platform,
-			PlatformFamily: hostInfo.PlatformFamily,
-			CPUUsage:       cpuPercent,
-			MemoryUsage:    float64(memorypercent),
-		})
+		// The remaining metrics are not available for every process or platform, so they are left 0 on errors
+		var rss, vms, threads, openFDs, readBytes, writeBytes, voluntary, involuntary int64
+		if memoryInfo, err := proc.MemoryInfo(); err == nil {
+			rss, vms = int64(memoryInfo.RSS), int64(memoryInfo.VMS)
+		}
+		if numThreads, err := proc.NumThreads(); err == nil {
+			threads = int64(numThreads)
+		}
+		if numFDs, err := proc.NumFDs(); err == nil {
+			openFDs = int64(numFDs)
+		}
+		if ioCounters, err := proc.IOCounters(); err == nil {
+			readBytes, writeBytes = int64(ioCounters.ReadBytes), int64(ioCounters.WriteBytes)
+		}
+		if ctxSwitches, err := proc.NumCtxSwitches(); err == nil {
+			voluntary, involuntary = ctxSwitches.Voluntary, ctxSwitches.Involuntary
+		}
+		// Processes of other users hide their executable and working directory, so they are left empty on errors
+		cmdline, _ := proc.Cmdline()
+		exe, _ := proc.Exe()
+		cwd, _ := proc.Cwd()
+		username, _ := proc.Username()
+
+		collected := Process{
+			PID:                    int64(proc.Pid),
+			PPID:                   int64(ppid),
+			Name:                   name,
+			Status:                 status,
+			CreatedTime:            createTime,
+			StoredTime:             sampledAt.UnixMilli(),
+			OS:                     hostInfo.OS,
+			Platform:               hostInfo.Platform,
+			PlatformFamily:         hostInfo.PlatformFamily,
+			CPUUsage:               cpuPercent,
+			IntervalCPUUsage:       p.cpu.intervalUsage(int64(proc.Pid), createTime, cpuSeconds, cpuPercent, sampledAt),
+			MemoryUsage:            float64(memorypercent),
+			RSSBytes:               rss,
+			VMSBytes:               vms,
+			Threads:                threads,
+			OpenFDs:                openFDs,
+			ReadBytes:              readBytes,
+			WriteBytes:             writeBytes,
+			VoluntaryCtxSwitches:   voluntary,
+			InvoluntaryCtxSwitches: involuntary,
+			Cmdline:                cmdline,
+			Exe:                    exe,
+			Cwd:                    cwd,
+			Username:               username,
+		}
+		// Cgroups exist only on Linux, elsewhere there is no proc filesystem to read them from
+		if runtime.GOOS == "linux" {
+			collected.setCgroup(readCgroup(DefaultProcRoot, collected.PID))
+		}
+
+		processInfo = append(processInfo, collected)
 	}
 
 	return processInfo, nil
@@ -20,3 +20,13 @@ func TestPsutilCollectWithRealOutput(t *testing.T) {
 	assert.NoError(t, err, "Collect method should not return an error")
 	assert.NotEmpty(t, processes, "Collect method should return list of processes")
 }
+
+func BenchmarkPsutilCollect(b *testing.B) {
+	psutil := NewPsutil(zerolog.Nop())
+
+	for i := 0; i < b.N; i++ {
+		if _, err := psutil.Collect(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package process
+
+import (
+	"sync"
+	"time"
+
+	"github.com/devzero-inc/oda/database"
+)
+
+const (
+	// RunStarted is the event of a process that appeared since the previous snapshot
+	RunStarted = "start"
+	// RunExited is the event of a process that disappeared since the previous snapshot
+	RunExited = "exit"
+)
+
+// Run is the model for the lifetime of a single process as observed by consecutive snapshots,
+// a process is identified by its PID and created time as PIDs are reused
+type Run struct {
+	Id          int64  `json:"id" db:"id"`
+	PID         int64  `json:"pid" db:"pid"`
+	PPID        int64  `json:"ppid" db:"ppid"`
+	CreatedTime int64  `json:"created_time" db:"created_time"`
+	Name        string `json:"name" db:"name"`
+	Cmdline     string `json:"cmdline" db:"cmdline"`
+	Username    string `json:"username" db:"username"`
+	ContainerID string `json:"container_id" db:"container_id"`
+	// CommandID is the UUID of the command that spawned the process, empty if not attributed
+	CommandID string `json:"command_id" db:"command_id"`
+	// FirstSeen and LastSeen are the stored times of the first and last snapshot containing the process
+	FirstSeen int64 `json:"first_seen" db:"first_seen"`
+	LastSeen  int64 `json:"last_seen" db:"last_seen"`
+	// ExitedTime is the stored time of the first snapshot not containing the process, 0 while it is running
+	ExitedTime int64 `json:"exited_time" db:"exited_time"`
+	// Samples is the number of snapshots containing the process
+	Samples int64 `json:"samples" db:"samples"`
+	// PeakCPUUsage, PeakMemoryUsage and PeakRSSBytes are the highest usage over all snapshots of the process
+	PeakCPUUsage    float64 `json:"peak_cpu_usage" db:"peak_cpu_usage"`
+	PeakMemoryUsage float64 `json:"peak_memory_usage" db:"peak_memory_usage"`
+	PeakRSSBytes    int64   `json:"peak_rss_bytes" db:"peak_rss_bytes"`
+}
+
+// RunEvent is the start or exit of a process detected by diffing consecutive snapshots
+type RunEvent struct {
+	Type        string
+	PID         int64
+	CreatedTime int64
+	Name        string
+	// Time is the stored time of the snapshot the event was detected in
+	Time int64
+}
+
+// runKey identifies a process across snapshots
+type runKey struct {
+	pid     int64
+	created int64
+}
+
+// RunTracker diffs consecutive snapshots to detect started and exited processes
+type RunTracker struct {
+	mutex sync.Mutex
+	// running is the processes of the previous snapshot, nil until the tracker is seeded or has seen a snapshot
+	running map[runKey]string
+}
+
+// NewRunTracker creates a new RunTracker instance
+func NewRunTracker() *RunTracker {
+	return &RunTracker{}
+}
+
+// Seeded reports whether the tracker knows the running processes, either seeded or from a previous snapshot
+func (t *RunTracker) Seeded() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.running != nil
+}
+
+// Seed sets the running processes, e.g. the runs left open when the collector was stopped,
+// so the processes that exited in the meantime are detected in the next snapshot
+func (t *RunTracker) Seed(runs []*Run) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.running = make(map[runKey]string, len(runs))
+	for _, run := range runs {
+		t.running[runKey{run.PID, run.CreatedTime}] = run.Name
+	}
+}
+
+// Track diffs the snapshot taken at sampledAt against the previous one,
+// returning a start event for each new process and an exit event for each missing one.
+// The created time estimated by ps can differ from the tracked one after a restart of the collector,
+// so a process with the same PID and name within the tolerance keeps the tracked created time.
+func (t *RunTracker) Track(processes []Process, sampledAt int64) []RunEvent {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var events []RunEvent
+
+	current := make(map[runKey]string, len(processes))
+	for i := range processes {
+		p := &processes[i]
+		p.CreatedTime = t.trackedCreatedTime(p.PID, p.CreatedTime, p.Name)
+		key := runKey{p.PID, p.CreatedTime}
+		current[key] = p.Name
+
+		if _, ok := t.running[key]; !ok {
+			events = append(events, RunEvent{Type: RunStarted, PID: p.PID, CreatedTime: p.CreatedTime, Name: p.Name, Time: sampledAt})
+		}
+	}
+
+	for key, name := range t.running {
+		if _, ok := current[key]; !ok {
+			events = append(events, RunEvent{Type: RunExited, PID: key.pid, CreatedTime: key.created, Name: name, Time: sampledAt})
+		}
+	}
+
+	t.running = current
+
+	return events
+}
+
+// trackedCreatedTime returns the created time of the running process with the same PID and name
+// if it is within the tolerance of the given one, otherwise the given one
+func (t *RunTracker) trackedCreatedTime(pid int64, created int64, name string) int64 {
+	if _, ok := t.running[runKey{pid, created}]; ok {
+		return created
+	}
+
+	for key, running := range t.running {
+		if key.pid == pid && running == name && (time.Duration(key.created-created)*time.Millisecond).Abs() <= startTimeTolerance {
+			return key.created
+		}
+	}
+
+	return created
+}
+
+// GetOpenRuns fetches the runs of the processes that have not exited yet
+func GetOpenRuns() ([]*Run, error) {
+	var runs []*Run
+
+	err := database.DB.Select(&runs, "SELECT * FROM process_runs WHERE exited_time = 0")
+	if err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// GetRunsForPeriod fetches the runs of the processes that were alive in a given period
+func GetRunsForPeriod(start int64, end int64) ([]*Run, error) {
+	return getRuns("first_seen <= ? AND last_seen >= ?", end, start)
+}
+
+// GetRunsForCommand fetches the runs of the processes spawned by the command with the given UUID
+func GetRunsForCommand(commandID string) ([]*Run, error) {
+	return getRuns("command_id = ?", commandID)
+}
+
+// getRuns fetches the runs matching the condition, the longest running first
+func getRuns(condition string, args ...interface{}) ([]*Run, error) {
+	var runs []*Run
+
+	query := `SELECT * FROM process_runs WHERE ` + condition + `
+ORDER BY last_seen - created_time DESC, peak_cpu_usage DESC
+LIMIT 100;`
+
+	err := database.DB.Select(&runs, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// RecordRuns records the snapshot and the events detected in it in the database in bulk, the run of each process
+// is created when it is first seen and its last seen time and peak usage are updated with every snapshot
+func RecordRuns(processes []Process, events []RunEvent) error {
+	upsertQuery := `INSERT INTO process_runs (pid, ppid, created_time, name, cmdline, username, container_id, command_id, first_seen, last_seen, samples, peak_cpu_usage, peak_memory_usage, peak_rss_bytes)
+	VALUES (:pid, :ppid, :created_time, :name, :cmdline, :username, :container_id, :command_id, :stored_time, :stored_time, 1, :interval_cpu_usage, :memory_usage, :rss_bytes)
+	ON CONFLICT (pid, created_time) DO UPDATE SET
+		last_seen = excluded.last_seen,
+		exited_time = 0,
+		samples = samples + 1,
+		command_id = CASE WHEN command_id = '' THEN excluded.command_id ELSE command_id END,
+		peak_cpu_usage = MAX(peak_cpu_usage, excluded.peak_cpu_usage),
+		peak_memory_usage = MAX(peak_memory_usage, excluded.peak_memory_usage),
+		peak_rss_bytes = MAX(peak_rss_bytes, excluded.peak_rss_bytes)`
+
+	// Begin a transaction
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareNamed(upsertQuery)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, process := range processes {
+		if _, err := stmt.Exec(process); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	for _, event := range events {
+		if event.Type != RunExited {
+			continue
+		}
+		if _, err := tx.Exec("UPDATE process_runs SET exited_time = ? WHERE pid = ? AND created_time = ?", event.Time, event.PID, event.CreatedTime); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// Commit the transaction after all updates
+	return tx.Commit()
+}
+
+// DeleteRunsByDays deletes the runs of processes that exited more than n days ago
+func DeleteRunsByDays(days int) error {
+	// Calculate the time when old records will be deleted
+	timeToDelete := time.Now().AddDate(0, 0, -days).UnixMilli()
+
+	result, err := database.DB.Exec("DELETE FROM process_runs WHERE exited_time != 0 AND exited_time < ?", timeToDelete)
+	if err != nil {
+		return err
+	}
+
+	_, err = result.RowsAffected()
+
+	return err
+}
@@ -0,0 +1,70 @@
+package process
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunTrackerTrack(t *testing.T) {
+	tracker := NewRunTracker()
+	assert.False(t, tracker.Seeded())
+
+	events := tracker.Track([]Process{
+		{PID: 1, CreatedTime: 100, Name: "init"},
+		{PID: 42, CreatedTime: 200, Name: "make"},
+	}, 1000)
+	assert.True(t, tracker.Seeded())
+	assert.ElementsMatch(t, []RunEvent{
+		{Type: RunStarted, PID: 1, CreatedTime: 100, Name: "init", Time: 1000},
+		{Type: RunStarted, PID: 42, CreatedTime: 200, Name: "make", Time: 1000},
+	}, events)
+
+	// The PID of make is reused by a new process, which is a different run
+	events = tracker.Track([]Process{
+		{PID: 1, CreatedTime: 100, Name: "init"},
+		{PID: 42, CreatedTime: 300, Name: "go"},
+	}, 2000)
+	assert.ElementsMatch(t, []RunEvent{
+		{Type: RunStarted, PID: 42, CreatedTime: 300, Name: "go", Time: 2000},
+		{Type: RunExited, PID: 42, CreatedTime: 200, Name: "make", Time: 2000},
+	}, events)
+
+	events = tracker.Track([]Process{
+		{PID: 1, CreatedTime: 100, Name: "init"},
+		{PID: 42, CreatedTime: 300, Name: "go"},
+	}, 3000)
+	assert.Empty(t, events, "Unchanged snapshot should not emit events")
+}
+
+func TestRunTrackerSeed(t *testing.T) {
+	tracker := NewRunTracker()
+	tracker.Seed([]*Run{{PID: 7, CreatedTime: 100, Name: "server"}, {PID: 8, CreatedTime: 100, Name: "worker"}})
+	assert.True(t, tracker.Seeded())
+
+	events := tracker.Track([]Process{{PID: 7, CreatedTime: 100, Name: "server"}}, 1000)
+	assert.Equal(t, []RunEvent{{Type: RunExited, PID: 8, CreatedTime: 100, Name: "worker", Time: 1000}}, events)
+}
+
+func TestRunTrackerSeedWithinTolerance(t *testing.T) {
+	tracker := NewRunTracker()
+	tracker.Seed([]*Run{{PID: 7, CreatedTime: 100000, Name: "server"}})
+
+	// The created time estimated after the restart is off by a second, it is still the same process
+	processes := []Process{{PID: 7, CreatedTime: 101000, Name: "server"}}
+	events := tracker.Track(processes, 1000)
+	assert.Empty(t, events, "Process within the tolerance should not exit and start again")
+	assert.Equal(t, int64(100000), processes[0].CreatedTime, "Process should keep the seeded created time")
+
+	processes = []Process{{PID: 7, CreatedTime: 99500, Name: "server"}}
+	events = tracker.Track(processes, 2000)
+	assert.Empty(t, events)
+	assert.Equal(t, int64(100000), processes[0].CreatedTime)
+
+	// A reused PID with another name is a different process even within the tolerance
+	events = tracker.Track([]Process{{PID: 7, CreatedTime: 100500, Name: "worker"}}, 3000)
+	assert.ElementsMatch(t, []RunEvent{
+		{Type: RunStarted, PID: 7, CreatedTime: 100500, Name: "worker", Time: 3000},
+		{Type: RunExited, PID: 7, CreatedTime: 100000, Name: "server", Time: 3000},
+	}, events)
+}
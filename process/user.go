@@ -0,0 +1,60 @@
+package process
+
+import (
+	"os/user"
+	"sync"
+	"unicode/utf8"
+)
+
+// maxCmdlineLength is the maximum stored length of a command line, longer ones like Java class paths are truncated
+const maxCmdlineLength = 4096
+
+// usernames resolves user IDs to names, caching the lookups as every sample contains the same few owners
+type usernames struct {
+	mutex sync.Mutex
+	names map[string]string
+}
+
+// newUsernames creates a new usernames instance
+func newUsernames() *usernames {
+	return &usernames{
+		names: make(map[string]string),
+	}
+}
+
+// lookup returns the name of the user with the ID, or the ID itself if the user is unknown
+func (u *usernames) lookup(uid string) string {
+	if uid == "" {
+		return ""
+	}
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if name, ok := u.names[uid]; ok {
+		return name
+	}
+
+	name := uid
+	if found, err := user.LookupId(uid); err == nil {
+		name = found.Username
+	}
+	u.names[uid] = name
+
+	return name
+}
+
+// TruncateCmdline limits the command line to maxCmdlineLength bytes without splitting a multi-byte character,
+// it is applied after redaction so a secret cut off at the limit is still detected
+func TruncateCmdline(cmdline string) string {
+	if len(cmdline) <= maxCmdlineLength {
+		return cmdline
+	}
+
+	end := maxCmdlineLength
+	for end > 0 && !utf8.RuneStart(cmdline[end]) {
+		end--
+	}
+
+	return cmdline[:end]
+}
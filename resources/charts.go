@@ -6,6 +6,7 @@ import (
 	"math"
 
 	"github.com/devzero-inc/oda/collector"
+	"github.com/devzero-inc/oda/host"
 	"github.com/devzero-inc/oda/process"
 )
 
@@ -114,17 +115,17 @@ func PrepareCPUTimeSeriesChartData(processData map[int64][]*process.Process) (st
 	}
 
 	var datasets []ChartDataDataset
-	for pid, processes := range processData {
+	for _, processes := range processData {
 		var dataPoints []DataPoint
 		for _, proc := range processes {
 			dataPoints = append(dataPoints, DataPoint{
 				X: proc.StoredTime,
-				Y: proc.CPUUsage,
+				Y: proc.IntervalCPUUsage,
 			})
 		}
 
 		datasets = append(datasets, ChartDataDataset{
-			Label:   fmt.Sprintf("%s - %d", processes[0].Name, pid),
+			Label:   fmt.Sprintf("%s - %d", processes[0].Name, processes[0].PID),
 			Data:    dataPoints,
 			Fill:    false,
 			Tension: 0.1,
@@ -181,7 +182,7 @@ func PrepareMemoryTimeSeriesChartData(processData map[int64][]*process.Process)
 	}
 
 	var datasets []ChartDataDataset
-	for pid, processes := range processData {
+	for _, processes := range processData {
 		var dataPoints []DataPoint
 		for _, proc := range processes {
 			dataPoints = append(dataPoints, DataPoint{
@@ -191,7 +192,7 @@ func PrepareMemoryTimeSeriesChartData(processData map[int64][]*process.Process)
 		}
 
 		datasets = append(datasets, ChartDataDataset{
-			Label:   fmt.Sprintf("%s - PID: %d", processes[0].Name, pid),
+			Label:   fmt.Sprintf("%s - PID: %d", processes[0].Name, processes[0].PID),
 			Data:    dataPoints,
 			Fill:    false,
 			Tension: 0.1,
@@ -357,10 +358,10 @@ func PrepareProcessesResourceUsageChartData(processes []*process.Process) (strin
 	var dataPoints []DataPoint
 	for _, proc := range processes {
 		dataPoint := DataPoint{
-			X:           proc.CPUUsage,    // x-axis represents CPU Usage
-			Y:           proc.MemoryUsage, // y-axis represents Memory Usage
+			X:           proc.IntervalCPUUsage, // x-axis represents CPU Usage
+			Y:           proc.MemoryUsage,      // y-axis represents Memory Usage
 			ProcessName: proc.Name,
-			R:           math.Sqrt(proc.CPUUsage * float64(proc.MemoryUsage)),
+			R:           math.Sqrt(proc.IntervalCPUUsage * float64(proc.MemoryUsage)),
 		}
 		dataPoints = append(dataPoints, dataPoint)
 	}
@@ -413,3 +414,114 @@ func PrepareProcessesResourceUsageChartData(processes []*process.Process) (strin
 
 	return string(chartJSON), nil
 }
+
+// hostSeries is a single line of a host metrics chart
+type hostSeries struct {
+	label string
+	value func(metrics *host.Metrics) float64
+}
+
+// PrepareHostCPUTimeSeriesChartData prepares and returns the chart data for the host CPU usage and load averages.
+func PrepareHostCPUTimeSeriesChartData(metrics []*host.Metrics) (string, error) {
+	return prepareHostTimeSeriesChartData(metrics, "CPU Usage (%) / Load", []hostSeries{
+		{"CPU Usage (%)", func(m *host.Metrics) float64 { return m.CPUUsage }},
+		{"Load 1m", func(m *host.Metrics) float64 { return m.Load1 }},
+		{"Load 5m", func(m *host.Metrics) float64 { return m.Load5 }},
+		{"Load 15m", func(m *host.Metrics) float64 { return m.Load15 }},
+	})
+}
+
+// PrepareHostMemoryTimeSeriesChartData prepares and returns the chart data for the host memory, swap and disk usage.
+func PrepareHostMemoryTimeSeriesChartData(metrics []*host.Metrics) (string, error) {
+	return prepareHostTimeSeriesChartData(metrics, "Usage (%)", []hostSeries{
+		{"Memory", func(m *host.Metrics) float64 { return percentage(m.MemoryUsed, m.MemoryTotal) }},
+		{"Swap", func(m *host.Metrics) float64 { return percentage(m.SwapUsed, m.SwapTotal) }},
+		{"Disk", func(m *host.Metrics) float64 { return percentage(m.DiskUsed, m.DiskTotal) }},
+	})
+}
+
+// PrepareHostNetworkTimeSeriesChartData prepares and returns the chart data for the host network throughput.
+func PrepareHostNetworkTimeSeriesChartData(metrics []*host.Metrics) (string, error) {
+	return prepareHostTimeSeriesChartData(metrics, "Throughput (KiB/s)", []hostSeries{
+		{"Received", func(m *host.Metrics) float64 { return m.NetworkReceiveRate / 1024 }},
+		{"Transmitted", func(m *host.Metrics) float64 { return m.NetworkTransmitRate / 1024 }},
+	})
+}
+
+// prepareHostTimeSeriesChartData prepares a line chart with one dataset per series of the host metrics.
+func prepareHostTimeSeriesChartData(metrics []*host.Metrics, title string, series []hostSeries) (string, error) {
+
+	if len(metrics) == 0 {
+		return "", nil
+	}
+
+	var datasets []ChartDataDataset
+	for _, s := range series {
+		var dataPoints []DataPoint
+		for _, m := range metrics {
+			dataPoints = append(dataPoints, DataPoint{
+				X: m.StoredTime,
+				Y: s.value(m),
+			})
+		}
+
+		datasets = append(datasets, ChartDataDataset{
+			Label:   s.label,
+			Data:    dataPoints,
+			Fill:    false,
+			Tension: 0.1,
+		})
+	}
+
+	chartData := ChartData{
+		Type: "line",
+		Data: ChartDataData{
+			Datasets: datasets,
+		},
+		Options: ChartOptions{
+			Scales: &ChartScales{
+				XAxes: ChartAxisOptions{
+					Type:     "linear",
+					Position: "bottom",
+					Title: &ChartAxisTitle{
+						Display: true,
+						Text:    "Time",
+					},
+				},
+				YAxes: ChartAxisOptions{
+					BeginAtZero: true,
+					Title: &ChartAxisTitle{
+						Display: true,
+						Text:    title,
+					},
+				},
+			},
+			Plugins: &ChartPlugins{
+				Legend: &ChartLegendOptions{
+					Display: true,
+				},
+				Tooltip: &ChartTooltipOptions{
+					Enabled: true,
+				},
+			},
+			MaintainAspectRatio: false,
+			Responsive:          true,
+		},
+	}
+
+	chartJSON, err := json.Marshal(chartData)
+	if err != nil {
+		return "", err
+	}
+
+	return string(chartJSON), nil
+}
+
+// percentage returns used as a percentage of total, 0 if the total is unknown
+func percentage(used int64, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(used) / float64(total) * 100
+}
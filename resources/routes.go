@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/devzero-inc/oda/collector"
+	"github.com/devzero-inc/oda/host"
 	"github.com/devzero-inc/oda/logging"
 	"github.com/devzero-inc/oda/process"
 )
@@ -19,6 +20,9 @@ import (
 //go:embed views/*
 var templateFS embed.FS
 
+// timeLayout is the layout of the times shown in the tables of the dashboard
+const timeLayout = "2006-01-02 15:04:05"
+
 func showError(w http.ResponseWriter) {
 	tmpl, err := template.ParseFS(templateFS, "views/error.html")
 	if err != nil {
@@ -60,6 +64,8 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	tag := r.URL.Query().Get("tag")
+
 	logging.Log.Debug().Msg("Creating waiting groups")
 
 	// Initialize wait group and channels for concurrent operations
@@ -67,17 +73,18 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
 	commandsChan := make(chan []*collector.Command, 1)
 	processesChan := make(chan []*process.Process, 1)
 	timeProcessesChan := make(chan map[int64][]*process.Process, 1)
+	pauseWindowsChan := make(chan []collector.PauseWindow, 1)
 
 	logging.Log.Debug().Msg("Fetching data concurrently")
 
 	// Increment wait group count for each concurrent operation
-	wg.Add(3)
+	wg.Add(4)
 
 	// Fetch commands concurrently
 	go func() {
 		logging.Log.Debug().Msg("Fetching commands")
 		defer wg.Done()
-		commands, err := collector.GetAllCommandsForPeriod(startMillis, endMillis)
+		commands, err := collector.GetAllCommandsForPeriod(startMillis, endMillis, tag)
 		logging.Log.Debug().Msg("Sending commands")
 		if err != nil {
 			logging.Log.Err(err).Msg("Failed to fetch commands")
@@ -118,6 +125,20 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
 		logging.Log.Debug().Msg("Fetched time processes")
 	}()
 
+	// Fetch pause windows concurrently
+	go func() {
+		logging.Log.Debug().Msg("Fetching pause windows")
+		defer wg.Done()
+		pauseWindows, err := collector.GetPauseWindowsForPeriod(startMillis, endMillis)
+		if err != nil {
+			logging.Log.Err(err).Msg("Failed to fetch pause windows")
+			pauseWindowsChan <- nil
+			return
+		}
+		pauseWindowsChan <- pauseWindows
+		logging.Log.Debug().Msg("Fetched pause windows")
+	}()
+
 	logging.Log.Debug().Msg("Waiting...")
 
 	// Wait for all goroutines to finish
@@ -125,11 +146,14 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
 	close(commandsChan)
 	close(processesChan)
 	close(timeProcessesChan)
+	close(pauseWindowsChan)
 
 	// Receive from channels
 	commands := <-commandsChan
 	processes := <-processesChan
 	timeProcesses := <-timeProcessesChan
+	// Missing pause windows only hide the gaps, the dashboard is still rendered
+	pauseWindows := preparePauseWindows(<-pauseWindowsChan, now)
 
 	// Check for errors after receiving data
 	if commands == nil || processes == nil || timeProcesses == nil {
@@ -183,11 +207,46 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
 		"MemoryTimeSeriesJSON": memoryResourceJson,
 		"StartTime":            start,
 		"EndTime":              end,
+		"Tag":                  tag,
+		"PauseWindows":         pauseWindows,
 	}); err != nil {
 		showError(w)
 	}
 }
 
+// pauseWindowRow is a pause window formatted for the dashboard
+type pauseWindowRow struct {
+	Kind  string
+	Start string
+	End   string
+}
+
+// preparePauseWindows formats the pause windows for the dashboard, windows that are still
+// open are shown as active unless they expired in the meantime
+func preparePauseWindows(windows []collector.PauseWindow, now time.Time) []pauseWindowRow {
+	rows := make([]pauseWindowRow, 0, len(windows))
+	for _, window := range windows {
+		row := pauseWindowRow{
+			Kind:  window.Kind,
+			Start: time.UnixMilli(window.StartTime).In(now.Location()).Format(timeLayout),
+			End:   "active",
+		}
+
+		switch {
+		case window.EndTime != 0:
+			row.End = time.UnixMilli(window.EndTime).In(now.Location()).Format(timeLayout)
+		case window.Until != 0 && window.Until <= now.UnixMilli():
+			row.End = time.UnixMilli(window.Until).In(now.Location()).Format(timeLayout)
+		case window.Until != 0:
+			row.End = "active until " + time.UnixMilli(window.Until).In(now.Location()).Format(timeLayout)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
 func commandHandler(w http.ResponseWriter, r *http.Request) {
 	queryParams := r.URL.Query()
 
@@ -226,8 +285,10 @@ func commandHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	tag := queryParams.Get("tag")
+
 	commands, err := collector.GetAllCommandsForCategoryForPeriod(
-		label, startMillis, endMillis)
+		label, startMillis, endMillis, tag)
 	if err != nil {
 		showError(w)
 		return
@@ -260,6 +321,8 @@ func commandHandler(w http.ResponseWriter, r *http.Request) {
 		"StartTime":    start,
 		"EndTime":      end,
 		"Commands":     commands,
+		"Label":        label,
+		"Tag":          tag,
 	}); err != nil {
 		showError(w)
 	}
@@ -291,17 +354,20 @@ func overviewHandler(w http.ResponseWriter, r *http.Request) {
 	var wg sync.WaitGroup
 	processesChan := make(chan []*process.Process, 1)
 	timeProcessesChan := make(chan map[int64][]*process.Process, 1)
+	containersChan := make(chan []*process.ContainerUsage, 1)
+	hostMetricsChan := make(chan []*host.Metrics, 1)
+	runsChan := make(chan []*process.Run, 1)
 
 	logging.Log.Debug().Msgf("Start time: %d, End time: %d", command.StartTime, command.EndTime)
 
 	// Increment wait group count for each concurrent operation
-	wg.Add(2)
+	wg.Add(5)
 
 	// Fetch processes concurrently
 	go func() {
 		logging.Log.Debug().Msg("Fetching overview processes")
 		defer wg.Done()
-		processes, err := process.GetAllProcessesForPeriod(command.StartTime, command.EndTime)
+		processes, err := getCommandProcesses(command)
 		logging.Log.Debug().Msg("Sending processes")
 		if err != nil {
 			logging.Log.Err(err).Msg("Failed to fetch processes")
@@ -316,7 +382,7 @@ func overviewHandler(w http.ResponseWriter, r *http.Request) {
 	go func() {
 		logging.Log.Debug().Msg("Fetching overview time processes")
 		defer wg.Done()
-		timeProcesses, err := process.GetTopProcessesAndMetrics(command.StartTime, command.EndTime)
+		timeProcesses, err := getCommandProcessMetrics(command)
 		logging.Log.Debug().Msg("Sending time processes")
 		if err != nil {
 			logging.Log.Err(err).Msg("Failed to fetch time processes")
@@ -327,16 +393,64 @@ func overviewHandler(w http.ResponseWriter, r *http.Request) {
 		logging.Log.Debug().Msg("Fetched time processes")
 	}()
 
+	// Fetch the usage grouped by container concurrently
+	go func() {
+		defer wg.Done()
+		containers, err := getCommandContainerUsage(command)
+		if err != nil {
+			logging.Log.Err(err).Msg("Failed to fetch container usage")
+			containersChan <- nil
+			return
+		}
+		containersChan <- containers
+	}()
+
+	// Fetch the host metrics of the command's time window concurrently
+	go func() {
+		defer wg.Done()
+		hostMetrics, err := host.GetMetricsForPeriod(command.StartTime, command.EndTime)
+		if err != nil {
+			logging.Log.Err(err).Msg("Failed to fetch host metrics")
+			hostMetricsChan <- nil
+			return
+		}
+		hostMetricsChan <- hostMetrics
+	}()
+
+	// Fetch the lifetimes of the processes concurrently
+	go func() {
+		defer wg.Done()
+		runs, err := getCommandRuns(command)
+		if err != nil {
+			logging.Log.Err(err).Msg("Failed to fetch process runs")
+			runsChan <- nil
+			return
+		}
+		runsChan <- runs
+	}()
+
 	logging.Log.Debug().Msg("Waiting...")
 
 	// Wait for all goroutines to finish
 	wg.Wait()
 	close(processesChan)
 	close(timeProcessesChan)
+	close(containersChan)
+	close(hostMetricsChan)
+	close(runsChan)
 
 	// Receive from channels
 	processes := <-processesChan
 	timeProcesses := <-timeProcessesChan
+	// The container usage is optional, the overview is rendered without it when it could not be fetched
+	// or when none of the processes ran in a container
+	containers := <-containersChan
+	if len(containers) == 1 && containers[0].ContainerID == "" {
+		containers = nil
+	}
+	// The host metrics are optional as well, they are only sampled on Linux
+	hostMetrics := <-hostMetricsChan
+	runs := <-runsChan
 
 	logging.Log.Debug().Msg("Checking for errors...")
 
@@ -374,6 +488,25 @@ func overviewHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	hostCPUJson, err := PrepareHostCPUTimeSeriesChartData(hostMetrics)
+	if err != nil {
+		logging.Log.Err(err).Msg("Failed to prepare host CPU time series")
+		showError(w)
+		return
+	}
+	hostMemoryJson, err := PrepareHostMemoryTimeSeriesChartData(hostMetrics)
+	if err != nil {
+		logging.Log.Err(err).Msg("Failed to prepare host memory time series")
+		showError(w)
+		return
+	}
+	hostNetworkJson, err := PrepareHostNetworkTimeSeriesChartData(hostMetrics)
+	if err != nil {
+		logging.Log.Err(err).Msg("Failed to prepare host network time series")
+		showError(w)
+		return
+	}
+
 	tmpl, err := template.ParseFS(templateFS, "views/overview.html")
 	if err != nil {
 		logging.Log.Err(err).Msg("Failed to render template")
@@ -386,6 +519,12 @@ func overviewHandler(w http.ResponseWriter, r *http.Request) {
 		"CPUTimeSeriesJSON":    cpuResourceJson,
 		"MemoryTimeSeriesJSON": memoryResourceJson,
 		"Processes":            processes,
+		"Containers":           containers,
+		"HostMetrics":          len(hostMetrics) > 0,
+		"Runs":                 prepareProcessRuns(runs, command, time.Local),
+		"HostCPUJSON":          hostCPUJson,
+		"HostMemoryJSON":       hostMemoryJson,
+		"HostNetworkJSON":      hostNetworkJson,
 		"ProcessJSON":          string(processesJson),
 	}); err != nil {
 		logging.Log.Err(err).Msg("Failed to render template")
@@ -393,9 +532,230 @@ func overviewHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// getCommandProcesses fetches the processes spawned by the command, commands recorded before
+// processes were attributed fall back to all processes in the command's time window.
+func getCommandProcesses(command *collector.Command) ([]*process.Process, error) {
+	if command.UUID != "" {
+		processes, err := process.GetProcessesForCommand(command.UUID)
+		if err != nil || len(processes) > 0 {
+			return processes, err
+		}
+	}
+
+	return process.GetAllProcessesForPeriod(command.StartTime, command.EndTime)
+}
+
+// getCommandProcessMetrics fetches the time series of processes spawned by the command, commands recorded
+// before processes were attributed fall back to all processes in the command's time window.
+func getCommandProcessMetrics(command *collector.Command) (map[int64][]*process.Process, error) {
+	if command.UUID != "" {
+		metrics, err := process.GetTopProcessesAndMetricsForCommand(command.UUID)
+		if err != nil || len(metrics) > 0 {
+			return metrics, err
+		}
+	}
+
+	return process.GetTopProcessesAndMetrics(command.StartTime, command.EndTime)
+}
+
+// getCommandRuns fetches the lifetimes of the processes spawned by the command, commands recorded
+// before processes were attributed fall back to all processes alive in the command's time window.
+func getCommandRuns(command *collector.Command) ([]*process.Run, error) {
+	if command.UUID != "" {
+		runs, err := process.GetRunsForCommand(command.UUID)
+		if err != nil || len(runs) > 0 {
+			return runs, err
+		}
+	}
+
+	return process.GetRunsForPeriod(command.StartTime, command.EndTime)
+}
+
+// processRunRow is the lifetime of a process formatted for the dashboard
+type processRunRow struct {
+	process.Run
+	Start    string
+	End      string
+	Lifetime string
+	// LifetimeMillis orders the lifetimes in the table
+	LifetimeMillis int64
+	// Appeared is set for processes started while the command was running
+	Appeared bool
+}
+
+// prepareProcessRuns formats the lifetimes of the processes for the dashboard, the lifetime of a process is
+// measured until it was last seen as it exited at some point before the next snapshot
+func prepareProcessRuns(runs []*process.Run, command *collector.Command, loc *time.Location) []processRunRow {
+	rows := make([]processRunRow, 0, len(runs))
+	for _, run := range runs {
+		lifetime := run.LastSeen - run.CreatedTime
+		row := processRunRow{
+			Run:            *run,
+			Start:          time.UnixMilli(run.CreatedTime).In(loc).Format(timeLayout),
+			End:            "running",
+			Lifetime:       (time.Duration(lifetime) * time.Millisecond).Round(time.Second).String(),
+			LifetimeMillis: lifetime,
+			Appeared:       run.CreatedTime >= command.StartTime,
+		}
+		if run.ExitedTime != 0 {
+			row.End = time.UnixMilli(run.ExitedTime).In(loc).Format(timeLayout)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// getCommandContainerUsage fetches the usage grouped by container of the processes spawned by the command, commands
+// recorded before processes were attributed fall back to all processes in the command's time window.
+func getCommandContainerUsage(command *collector.Command) ([]*process.ContainerUsage, error) {
+	if command.UUID != "" {
+		containers, err := process.GetContainerUsageForCommand(command.UUID)
+		if err != nil || len(containers) > 0 {
+			return containers, err
+		}
+	}
+
+	return process.GetContainerUsageForPeriod(command.StartTime, command.EndTime)
+}
+
+func sessionsHandler(w http.ResponseWriter, r *http.Request) {
+	loc, _ := time.LoadLocation("Local")
+	now := time.Now().In(loc)
+
+	var startMillis, endMillis int64
+
+	start := r.URL.Query().Get("start")
+	if start == "" {
+		// Default start time to the start of today (00:00:00)
+		startTime := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+		startMillis = startTime.UnixMilli()
+	} else if parsedTime, err := time.ParseInLocation("2006-01-02T15:04", start, loc); err == nil {
+		startMillis = parsedTime.UnixMilli()
+	}
+
+	end := r.URL.Query().Get("end")
+	if end == "" {
+		// Default end time to the current time
+		endMillis = now.UnixMilli()
+	} else if parsedTime, err := time.ParseInLocation("2006-01-02T15:04", end, loc); err == nil {
+		endMillis = parsedTime.UnixMilli()
+	}
+
+	sessions, err := collector.GetSessionsForPeriod(startMillis, endMillis)
+	if err != nil {
+		showError(w)
+		return
+	}
+
+	rows := make([]sessionRow, 0, len(sessions))
+	for _, session := range sessions {
+		rows = append(rows, newSessionRow(session, loc))
+	}
+
+	tmpl, err := template.ParseFS(templateFS, "views/sessions.html")
+	if err != nil {
+		logging.Log.Err(err).Msg("Failed to render template")
+		showError(w)
+		return
+	}
+
+	if start == "" {
+		start = time.UnixMilli(startMillis).UTC().Format("2006-01-02T15:04")
+	}
+
+	if end == "" {
+		end = time.UnixMilli(endMillis).UTC().Format("2006-01-02T15:04")
+	}
+
+	if err := tmpl.Execute(w, map[string]interface{}{
+		"Sessions":  rows,
+		"StartTime": start,
+		"EndTime":   end,
+	}); err != nil {
+		logging.Log.Err(err).Msg("Failed to render template")
+		showError(w)
+	}
+}
+
+func sessionHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		showError(w)
+		return
+	}
+
+	loc, _ := time.LoadLocation("Local")
+
+	session, err := collector.GetSession(id)
+	if err != nil {
+		showError(w)
+		return
+	}
+
+	commands, err := collector.GetCommandsForSession(id)
+	if err != nil {
+		showError(w)
+		return
+	}
+
+	rows := make([]sessionCommandRow, 0, len(commands))
+	for _, command := range commands {
+		rows = append(rows, sessionCommandRow{
+			Command: command,
+			Start:   time.UnixMilli(command.StartTime).In(loc).Format(timeLayout),
+		})
+	}
+
+	tmpl, err := template.ParseFS(templateFS, "views/session.html")
+	if err != nil {
+		logging.Log.Err(err).Msg("Failed to render template")
+		showError(w)
+		return
+	}
+
+	if err := tmpl.Execute(w, map[string]interface{}{
+		"Session":  newSessionRow(*session, loc),
+		"Commands": rows,
+	}); err != nil {
+		logging.Log.Err(err).Msg("Failed to render template")
+		showError(w)
+	}
+}
+
+// sessionRow is a shell session formatted for the dashboard
+type sessionRow struct {
+	collector.Session
+	Start string
+	End   string
+}
+
+// newSessionRow formats the session for the dashboard, sessions that did not end yet are shown as active
+func newSessionRow(session collector.Session, loc *time.Location) sessionRow {
+	row := sessionRow{
+		Session: session,
+		Start:   time.UnixMilli(session.StartTime).In(loc).Format(timeLayout),
+		End:     "active",
+	}
+	if session.EndTime != 0 {
+		row.End = time.UnixMilli(session.EndTime).In(loc).Format(timeLayout)
+	}
+
+	return row
+}
+
+// sessionCommandRow is a command of a session formatted for the dashboard
+type sessionCommandRow struct {
+	collector.Command
+	Start string
+}
+
 // Serve registers the HTTP handlers for the application
 func Serve() {
 	http.HandleFunc("/", homeHandler)
 	http.HandleFunc("/command", commandHandler)
 	http.HandleFunc("/overview", overviewHandler)
+	http.HandleFunc("/sessions", sessionsHandler)
+	http.HandleFunc("/session", sessionHandler)
 }
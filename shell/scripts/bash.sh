@@ -6,20 +6,51 @@ generate_ppid() {
   echo "$$"
 }
 
+# The session is not exported, so every shell registers its own session
+ODA_SESSION=$(generate_uuid)
+
+oda_session_end() {
+    if [[ -n "$ODA_INCOGNITO" ]]; then
+        return
+    fi
+    "{{.ExePath}}" emit session_end --socket="{{.SocketPath}}" --session="$ODA_SESSION" --pid="$$" --timestamp="$EPOCHREALTIME"
+}
+
+if [[ -z "$ODA_INCOGNITO" ]]; then
+    "{{.ExePath}}" emit session_start --socket="{{.SocketPath}}" --session="$ODA_SESSION" --pid="$$" --user="$USER" --tty="$(tty -s && tty)" --shell="bash" --shell-version="$BASH_VERSION" --ssh-connection="$SSH_CONNECTION" --tmux-pane="$TMUX_PANE" --timestamp="$EPOCHREALTIME"
+fi
+
+# Existing EXIT traps are kept, the collector ends the session once the shell is gone in that case
+if [[ -z "$(trap -p EXIT)" ]]; then
+    trap 'oda_session_end' EXIT
+fi
+
 preexec_invoke_exec() {
+    # Commands are not recorded in shells started with oda incognito
+    if [[ -n "$ODA_INCOGNITO" ]]; then
+        return
+    fi
     # Avoid running preexec_invoke_exec for PROMPT_COMMAND
     if [[ "$BASH_COMMAND" != "$PROMPT_COMMAND" ]]; then
+        # EPOCHREALTIME is available since bash 5, collector falls back to the receive time when empty
+        local timestamp="$EPOCHREALTIME"
         export UUID=$(generate_uuid)
         export PID=$(generate_ppid)
         export LAST_COMMAND="$BASH_COMMAND"
         # Send a start execution message
-        {{.CommandScriptPath}} "start" "$LAST_COMMAND" "$PWD" "$USER" "$UUID" "$PID"
+        "{{.ExePath}}" emit start --socket="{{.SocketPath}}" --command="$LAST_COMMAND" --directory="$PWD" --user="$USER" --uuid="$UUID" --pid="$PID" --session="$ODA_SESSION" --timestamp="$timestamp"
     fi
 }
 trap 'preexec_invoke_exec' DEBUG
 
 precmd_invoke_cmd() {
     local exit_status=$?
+    local timestamp="$EPOCHREALTIME"
+
+    if [[ -n "$ODA_INCOGNITO" ]]; then
+        return $exit_status
+    fi
+
     local result="success"
 
     if [[ $exit_status -ne 0 ]]; then
@@ -27,7 +58,7 @@ precmd_invoke_cmd() {
     fi
 
     # Send an end execution message with the result and exit status
-    {{.CommandScriptPath}} "end" "$LAST_COMMAND" "$PWD" "$USER" "$UUID" "$PID" "$result" "$exit_status"
+    "{{.ExePath}}" emit end --socket="{{.SocketPath}}" --command="$LAST_COMMAND" --directory="$PWD" --user="$USER" --uuid="$UUID" --pid="$PID" --result="$result" --status="$exit_status" --session="$ODA_SESSION" --timestamp="$timestamp"
 }
 
 # Update PROMPT_COMMAND to invoke precmd_invoke_cmd
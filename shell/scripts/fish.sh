@@ -6,16 +6,39 @@ function generate_ppid
     echo (echo %self)
 end
 
+# The session is not exported, so every shell registers its own session
+set -g ODA_SESSION (generate_uuid)
+
+function oda_session_end --on-event fish_exit
+    if set -q ODA_INCOGNITO
+        return
+    end
+    "{{.ExePath}}" emit session_end --socket="{{.SocketPath}}" --session="$ODA_SESSION" --pid=(generate_ppid)
+end
+
+if not set -q ODA_INCOGNITO
+    "{{.ExePath}}" emit session_start --socket="{{.SocketPath}}" --session="$ODA_SESSION" --pid=(generate_ppid) --user="$USER" --tty=(tty -s; and tty) --shell="fish" --shell-version="$version" --ssh-connection="$SSH_CONNECTION" --tmux-pane="$TMUX_PANE"
+end
+
 function fish_preexec --on-event fish_preexec
+    # Commands are not recorded in shells started with oda incognito
+    if set -q ODA_INCOGNITO
+        return
+    end
     set -gx LAST_COMMAND $argv[1]
     set -gx UUID (generate_uuid)
     set -gx PID (generate_ppid)
     # Send a start execution message
-    {{.CommandScriptPath}} "start" "$LAST_COMMAND" "$PWD" "$USER" "$UUID" "$PID"
+    "{{.ExePath}}" emit start --socket="{{.SocketPath}}" --command="$LAST_COMMAND" --directory="$PWD" --user="$USER" --uuid="$UUID" --pid="$PID" --session="$ODA_SESSION"
 end
 
 function fish_postexec --on-event fish_postexec
     set -l exit_status $status
+    if set -q ODA_INCOGNITO
+        return
+    end
+    # CMD_DURATION is the duration of the last command in milliseconds
+    set -l duration $CMD_DURATION
     set -l result "success"
     
     if test $exit_status -ne 0
@@ -23,5 +46,5 @@ function fish_postexec --on-event fish_postexec
     end
     
     # Send an end execution message with result and exit status
-    {{.CommandScriptPath}} "end" "$LAST_COMMAND" "$PWD" "$USER" "$UUID" "$PID" "$result" "$exit_status"
+    "{{.ExePath}}" emit end --socket="{{.SocketPath}}" --command="$LAST_COMMAND" --directory="$PWD" --user="$USER" --uuid="$UUID" --pid="$PID" --result="$result" --status="$exit_status" --duration="$duration" --session="$ODA_SESSION"
 end
@@ -1,3 +1,6 @@
+# Provides EPOCHREALTIME for high resolution timestamps
+zmodload zsh/datetime 2>/dev/null
+
 generate_uuid() {
   echo "$(date +%s)-$$-$RANDOM"
 }
@@ -6,16 +9,44 @@ generate_ppid() {
   echo "$$"
 }
 
+# The session is not exported, so every shell registers its own session
+ODA_SESSION=$(generate_uuid)
+
+oda_session_end() {
+  if [[ -n "$ODA_INCOGNITO" ]]; then
+    return
+  fi
+  "{{.ExePath}}" emit session_end --socket="{{.SocketPath}}" --session="$ODA_SESSION" --pid="$$" --timestamp="$EPOCHREALTIME"
+}
+
+if [[ -z "$ODA_INCOGNITO" ]]; then
+  "{{.ExePath}}" emit session_start --socket="{{.SocketPath}}" --session="$ODA_SESSION" --pid="$$" --user="$USER" --tty="$(tty -s && tty)" --shell="zsh" --shell-version="$ZSH_VERSION" --ssh-connection="$SSH_CONNECTION" --tmux-pane="$TMUX_PANE" --timestamp="$EPOCHREALTIME"
+fi
+
+autoload -Uz add-zsh-hook
+add-zsh-hook zshexit oda_session_end
+
 preexec() {
+  # Commands are not recorded in shells started with oda incognito
+  if [[ -n "$ODA_INCOGNITO" ]]; then
+    return
+  fi
+  local timestamp="$EPOCHREALTIME"
   export LAST_COMMAND=$1
   UUID=$(generate_uuid)
   PID=$(generate_ppid)
   # Send a start execution message
-  {{.CommandScriptPath}} "start" "$LAST_COMMAND" "$PWD" "$USER" "$UUID" "$PID"
+  "{{.ExePath}}" emit start --socket="{{.SocketPath}}" --command="$LAST_COMMAND" --directory="$PWD" --user="$USER" --uuid="$UUID" --pid="$PID" --session="$ODA_SESSION" --timestamp="$timestamp"
 }
 
 precmd() {
   local exit_status=$?
+  local timestamp="$EPOCHREALTIME"
+
+  if [[ -n "$ODA_INCOGNITO" ]]; then
+    return $exit_status
+  fi
+
   local result="success"
   
   if [[ $exit_status -ne 0 ]]; then
@@ -23,5 +54,5 @@ precmd() {
   fi
   
   # Send an end execution message with result and exit status
-  {{.CommandScriptPath}} "end" "$LAST_COMMAND" "$PWD" "$USER" "$UUID" "$PID" "$result" "$exit_status"
+  "{{.ExePath}}" emit end --socket="{{.SocketPath}}" --command="$LAST_COMMAND" --directory="$PWD" --user="$USER" --uuid="$UUID" --pid="$PID" --result="$result" --status="$exit_status" --session="$ODA_SESSION" --timestamp="$timestamp"
 }
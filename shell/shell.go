@@ -10,7 +10,6 @@ import (
 	"strings"
 	"text/template"
 
-	"github.com/devzero-inc/oda/collector"
 	"github.com/devzero-inc/oda/config"
 	"github.com/devzero-inc/oda/util"
 
@@ -21,8 +20,8 @@ import (
 const (
 	odaScript       = "oda.sh"
 	execPermissions = 0755
-	CollectorName   = "collector.sh"
-	CollectorScript = "scripts/collector.sh"
+	// CollectorName is the legacy socket client script, hooks now use `oda emit` directly
+	CollectorName = "collector.sh"
 )
 
 var (
@@ -72,6 +71,8 @@ type Config struct {
 	SudoExecUser  *user.User
 	OdaDir        string
 	HomeDir       string
+	ExePath       string
+	SocketPath    string
 }
 
 // Shell is the shell configuration
@@ -94,27 +95,6 @@ func (s *Shell) InstallShellConfiguration() error {
 
 	filePath := filepath.Join(s.Config.OdaDir, shellScriptName[s.Config.ShellType])
 
-	collectorFilePath := filepath.Join(s.Config.OdaDir, CollectorName)
-
-	cmdTmpl, err := template.ParseFS(templateFS, CollectorScript)
-	if err != nil {
-		s.logger.Err(err).Msg("Failed to parse collector template")
-		return err
-	}
-
-	var cmdContent bytes.Buffer
-	if err := cmdTmpl.Execute(&cmdContent, map[string]interface{}{
-		"SocketPath": collector.SocketPath,
-	}); err != nil {
-		s.logger.Err(err).Msg("Failed to execute cmd template")
-		return err
-	}
-
-	if err := util.WriteFileAndChown(collectorFilePath, cmdContent.Bytes(), execPermissions, s.Config.SudoExecUser); err != nil {
-		s.logger.Err(err).Msg("Failed to write collector files")
-		return err
-	}
-
 	shellTmplLocation, ok := templateSources[s.Config.ShellType]
 	if !ok {
 		s.logger.Error().Msg("Unsupported shell")
@@ -129,7 +109,8 @@ func (s *Shell) InstallShellConfiguration() error {
 
 	var shellContent bytes.Buffer
 	if err := shellTmpl.Execute(&shellContent, map[string]interface{}{
-		"CommandScriptPath": collectorFilePath,
+		"ExePath":    s.Config.ExePath,
+		"SocketPath": s.Config.SocketPath,
 	}); err != nil {
 		s.logger.Err(err).Msg("Failed to execute shell template")
 		return err
@@ -155,8 +136,9 @@ func (s *Shell) DeleteShellConfiguration() error {
 		return err
 	}
 
-	filePath = filepath.Join(s.Config.OdaDir, "collector.sh")
-	if err := os.Remove(filePath); err != nil {
+	// collector.sh is only present on installations that predate `oda emit`
+	filePath = filepath.Join(s.Config.OdaDir, CollectorName)
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
 		s.logger.Err(err).Msg("Failed to remove shell configuration")
 		return err
 	}
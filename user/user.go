@@ -53,6 +53,8 @@ type Config struct {
 	IsRoot bool `json:"is_root" db:"is_root"`
 	// ExePath is the path to the oda binary
 	ExePath string `json:"exe_path" db:"exe_path"`
+	// SocketPath is the path of the collector socket, shared by the shell hooks and the daemon
+	SocketPath string `json:"socket_path" db:"socket_path"`
 	// ShellTypeToLocation is a map of shell type to location
 	ShellTypeToLocation map[config.ShellType]string `json:"shell_type_to_location" db:"shell_type_to_location"`
 	// User is the user that executed the command (if sudo)
@@ -74,8 +76,8 @@ func GetConfig() (*Config, error) {
 
 // InsertConfig inserts Config used to configure the system
 func InsertConfig(osConfig Config) error {
-	query := `INSERT INTO config (os, os_name, home_dir, oda_dir, is_root, exe_path) 
-			  VALUES (:os, :os_name, :home_dir, :oda_dir, :is_root, :exe_path)`
+	query := `INSERT INTO config (os, os_name, home_dir, oda_dir, is_root, exe_path, socket_path) 
+			  VALUES (:os, :os_name, :home_dir, :oda_dir, :is_root, :exe_path, :socket_path)`
 
 	_, err := database.DB.NamedExec(query, osConfig)
 	if err != nil {
@@ -116,7 +118,8 @@ func UpdateConfig(osConfig Config) error {
                 home_dir = :home_dir, 
                 oda_dir = :oda_dir, 
                 is_root = :is_root, 
-                exe_path = :exe_path
+                exe_path = :exe_path,
+                socket_path = :socket_path
               WHERE id = :id`
 
 	_, err := database.DB.NamedExec(query, osConfig)
@@ -265,6 +268,9 @@ func CompareConfig(existingConf, currentConf *Config) (bool, []string) {
 	if existingConf.ExePath != currentConf.ExePath {
 		diffs = append(diffs, fmt.Sprintf("Executable Path changed from %s to %s", existingConf.ExePath, currentConf.ExePath))
 	}
+	if existingConf.SocketPath != currentConf.SocketPath {
+		diffs = append(diffs, fmt.Sprintf("Socket Path changed from %s to %s", existingConf.SocketPath, currentConf.SocketPath))
+	}
 
 	return len(diffs) > 0, diffs
 }
@@ -2,10 +2,7 @@ package util
 
 import (
 	"bufio"
-	"bytes"
-	"fmt"
 	"os"
-	"os/exec"
 	"os/user"
 	"strconv"
 	"strings"
@@ -126,37 +123,3 @@ func AppendToFile(filePath, content string) error {
 	}
 	return nil
 }
-
-// GetRepoNameFromConfig checks if the current directory (or any of its parent directories) is inside a Git repository.
-// If inside a Git repo, it retrieves and returns the repository name.
-func GetRepoNameFromConfig(path string) (string, error) {
-	// Check if we're inside a Git worktree
-	cmd := exec.Command("git", "-C", path, "rev-parse", "--is-inside-work-tree")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	err := cmd.Run()
-	if err != nil || strings.TrimSpace(out.String()) != "true" {
-		return "", fmt.Errorf("not inside a Git repository: %w", err)
-	}
-
-	// Retrieve the remote origin URL
-	cmd = exec.Command("git", "-C", path, "config", "--get", "remote.origin.url")
-	out.Reset()
-	cmd.Stdout = &out
-	err = cmd.Run()
-	if err != nil {
-		return "", fmt.Errorf("could not get remote origin URL: %w", err)
-	}
-
-	url := strings.TrimSpace(out.String())
-	if url == "" {
-		return "", fmt.Errorf("no origin URL found in Git configuration")
-	}
-
-	// Remove .git suffix if present and extract repo name from the URL
-	url = strings.TrimSuffix(url, ".git")
-	parts := strings.Split(url, "/")
-	repoName := parts[len(parts)-1]
-
-	return repoName, nil
-}
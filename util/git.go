@@ -0,0 +1,266 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// gitStatusTimeout is the maximum time git status may take, it runs on every command start
+// so a huge repository or a hung filesystem must not hold up the tracking of the command
+const gitStatusTimeout = 300 * time.Millisecond
+
+// GitContext is the state of the Git repository a command was executed in
+type GitContext struct {
+	// Root is the top level directory of the working tree
+	Root string
+	// Path is the directory relative to the repository root
+	Path string
+	// Branch is the checked out branch, empty for a detached HEAD
+	Branch string
+	// Commit is the SHA of HEAD, empty for a repository without commits
+	Commit string
+	// Dirty reports whether tracked files have uncommitted changes, nil if git status failed or timed out
+	Dirty *bool
+	// RemoteURL is the raw URL of the origin remote
+	RemoteURL string
+	// RepositoryID is the normalized origin remote in the form of host/org/repo
+	RepositoryID string
+}
+
+// Name returns the short name of the repository, the last segment of the repository identifier
+func (g *GitContext) Name() string {
+	if g.RepositoryID == "" {
+		return ""
+	}
+	return g.RepositoryID[strings.LastIndex(g.RepositoryID, "/")+1:]
+}
+
+// GetGitContext checks if the directory (or any of its parent directories) is inside a Git repository
+// and returns the repository state. Everything apart from the dirty state is read directly from the
+// .git directory, so at most a single git process is spawned per call.
+func GetGitContext(path string) (*GitContext, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	root, gitDir, err := findGitDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	commonDir := resolveCommonDir(gitDir)
+
+	ctx := &GitContext{Root: root}
+
+	if rel, err := filepath.Rel(root, path); err == nil {
+		ctx.Path = filepath.ToSlash(rel)
+	}
+
+	ctx.Branch, ctx.Commit = readHead(gitDir, commonDir)
+
+	ctx.RemoteURL = readRemoteURL(filepath.Join(commonDir, "config"), "origin")
+	ctx.RepositoryID = NormalizeRemoteURL(ctx.RemoteURL)
+
+	ctx.Dirty = isWorkTreeDirty(root)
+
+	return ctx, nil
+}
+
+// findGitDir walks up from the path until it finds the .git entry, which is either a directory
+// or, for worktrees and submodules, a file pointing to the actual Git directory.
+func findGitDir(path string) (string, string, error) {
+	dir := path
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		info, err := os.Stat(dotGit)
+		if err == nil {
+			if info.IsDir() {
+				return dir, dotGit, nil
+			}
+
+			gitDir, err := readGitDirFile(dotGit)
+			if err != nil {
+				return "", "", err
+			}
+			return dir, gitDir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", fmt.Errorf("not inside a Git repository: %s", path)
+		}
+		dir = parent
+	}
+}
+
+// readGitDirFile reads the `gitdir: <path>` reference of a .git file
+func readGitDirFile(dotGit string) (string, error) {
+	content, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", err
+	}
+
+	gitDir, found := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+	if !found {
+		return "", fmt.Errorf("invalid .git file: %s", dotGit)
+	}
+
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(dotGit), gitDir)
+	}
+
+	return filepath.Clean(gitDir), nil
+}
+
+// resolveCommonDir returns the directory holding the config and refs shared between worktrees
+func resolveCommonDir(gitDir string) string {
+	content, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+
+	commonDir := strings.TrimSpace(string(content))
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(gitDir, commonDir)
+	}
+
+	return filepath.Clean(commonDir)
+}
+
+// readHead returns the checked out branch and the commit HEAD points to
+func readHead(gitDir, commonDir string) (string, string) {
+	content, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", ""
+	}
+
+	head := strings.TrimSpace(string(content))
+	ref, symbolic := strings.CutPrefix(head, "ref:")
+	if !symbolic {
+		// Detached HEAD
+		return "", head
+	}
+
+	ref = strings.TrimSpace(ref)
+	branch := strings.TrimPrefix(ref, "refs/heads/")
+
+	return branch, resolveRef(ref, gitDir, commonDir)
+}
+
+// resolveRef resolves the ref to a commit, looking at loose refs first and packed refs after
+func resolveRef(ref, gitDir, commonDir string) string {
+	for _, dir := range []string{gitDir, commonDir} {
+		if content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref))); err == nil {
+			return strings.TrimSpace(string(content))
+		}
+	}
+
+	file, err := os.Open(filepath.Join(commonDir, "packed-refs"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		commit, name, found := strings.Cut(scanner.Text(), " ")
+		if found && name == ref {
+			return commit
+		}
+	}
+
+	return ""
+}
+
+// readRemoteURL reads the URL of the remote from the Git config file
+func readRemoteURL(configPath, remote string) string {
+	file, err := os.Open(configPath)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	section := fmt.Sprintf(`[remote "%s"]`, remote)
+	inSection := false
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inSection = line == section
+			continue
+		}
+		if !inSection {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if found && strings.TrimSpace(key) == "url" {
+			return strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+
+	return ""
+}
+
+// isWorkTreeDirty reports whether tracked files in the working tree have uncommitted changes, nil if it is unknown.
+// It runs at the same time as the user's own git commands, so it must not take the index lock to refresh the index.
+func isWorkTreeDirty(root string) *bool {
+	ctx, cancel := context.WithTimeout(context.Background(), gitStatusTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "--no-optional-locks", "-C", root, "status", "--porcelain", "--untracked-files=no")
+	// Processes started by git, like an fsmonitor hook, could keep the output open after git is killed
+	cmd.WaitDelay = gitStatusTimeout
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return nil
+	}
+
+	dirty := strings.TrimSpace(out.String()) != ""
+
+	return &dirty
+}
+
+// NormalizeRemoteURL converts a Git remote URL into the host/org/repo form, so the same repository
+// is identified the same way regardless of the protocol or credentials used to clone it.
+func NormalizeRemoteURL(remote string) string {
+	remote = strings.TrimSpace(remote)
+	if remote == "" {
+		return ""
+	}
+
+	var host, path string
+
+	if local, found := strings.CutPrefix(remote, "file://"); found {
+		path = local
+	} else if u, err := url.Parse(remote); err == nil && u.Scheme != "" && u.Host != "" {
+		// https://host/org/repo, ssh://git@host:22/org/repo, git://host/org/repo
+		host, path = u.Hostname(), u.Path
+	} else if at, rest, found := strings.Cut(remote, ":"); found && !strings.Contains(at, "/") {
+		// scp-like syntax: git@host:org/repo
+		host, path = at[strings.LastIndex(at, "@")+1:], rest
+	} else {
+		// Local path
+		path = remote
+	}
+
+	path = strings.TrimSuffix(strings.TrimRight(path, "/"), ".git")
+
+	if host == "" {
+		return path
+	}
+
+	return strings.ToLower(host) + "/" + strings.TrimLeft(path, "/")
+}
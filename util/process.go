@@ -0,0 +1,27 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"syscall"
+)
+
+// IsProcessRunning checks if a process with the given PID exists, without sending any signal to it
+func IsProcessRunning(pid int64) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	err := syscall.Kill(int(pid), syscall.Signal(0))
+	// EPERM means the process exists, but it belongs to another user
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// HashCmdline returns a short hash of the command line, it tells apart processes that changed their command line
+// without using the whole command line as a key
+func HashCmdline(cmdline string) string {
+	sum := sha256.Sum256([]byte(cmdline))
+
+	return hex.EncodeToString(sum[:8])
+}